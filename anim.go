@@ -0,0 +1,177 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+var reduceMotion int32 // See SetReduceMotion.
+
+// SetReduceMotion disables all window animations when set to true,
+// for users who prefer less motion on screen. It's safe to call from any goroutine.
+func SetReduceMotion(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&reduceMotion, v)
+}
+
+// GetReduceMotion returns true if window animations are disabled, see SetReduceMotion.
+func GetReduceMotion() bool {
+	return atomic.LoadInt32(&reduceMotion) != 0
+}
+
+// AnimationOptions are the window animation settings of a desktop.
+type AnimationOptions struct {
+	Duration  time.Duration // Length of each animation, 0 uses 150ms.
+	FrameRate int           // Most frames per second, 0 uses 30; lower it for slow connections.
+}
+
+// windowAnimation is an outline moving from one rect to the window's rect,
+// the window is not drawn until it's done.
+type windowAnimation struct {
+	win                        *Window
+	fromX, fromY, fromW, fromH int
+	toX, toY, toW, toH         int
+	start                      time.Time
+	progress                   float64 // 0 to 1.
+}
+
+// SetAnimation enables window animations with the options, or disables them if nil.
+// Animations need the application, see SetApplication.
+func (d *Desktop) SetAnimation(opts *AnimationOptions) *Desktop {
+	d.animOpts = opts
+	return d
+}
+
+// AnimateWindow animates an outline from the rect on the screen to where the window is now,
+// such as when the window manager changes the window's state:
+// minimizing collapses into the minimized window, restoring grows out of it.
+// The desktop has no taskbar; one could animate from its button after restoring a window.
+// Nothing happens if animations are disabled or SetReduceMotion is on.
+func (d *Desktop) AnimateWindow(win *Window, x, y, width, height int) {
+	if d.animOpts == nil || d.app == nil || GetReduceMotion() || !d.init {
+		return
+	}
+	anim := &windowAnimation{win: win, fromX: x, fromY: y, fromW: width, fromH: height, start: time.Now()}
+	anim.toX, anim.toY, anim.toW, anim.toH = d.WindowToScreen(win)
+	for i, xanim := range d.anims {
+		if xanim.win == win {
+			d.anims[i] = anim // Replace the old one.
+			return
+		}
+	}
+	d.anims = append(d.anims, anim)
+	if len(d.anims) == 1 {
+		d.nextAnimationFrame()
+	}
+}
+
+func (d *Desktop) animationDuration() time.Duration {
+	if d.animOpts != nil && d.animOpts.Duration > 0 {
+		return d.animOpts.Duration
+	}
+	return 150 * time.Millisecond
+}
+
+func (d *Desktop) nextAnimationFrame() {
+	frameRate := 30
+	if d.animOpts != nil && d.animOpts.FrameRate > 0 {
+		frameRate = d.animOpts.FrameRate
+	}
+	app := d.app
+	d.animTimer = time.AfterFunc(time.Second/time.Duration(frameRate), func() {
+		app.QueueUpdateDraw(d.stepAnimations)
+	})
+}
+
+// stepAnimations is called from the application's goroutine for each frame.
+func (d *Desktop) stepAnimations() {
+	now := time.Now()
+	duration := d.animationDuration()
+	anims := d.anims[:0]
+	for _, anim := range d.anims {
+		anim.progress = float64(now.Sub(anim.start)) / float64(duration)
+		if anim.progress < 1 && anim.win.desktop == d && d.animOpts != nil && !GetReduceMotion() {
+			anims = append(anims, anim)
+		}
+	}
+	d.anims = anims
+	if len(d.anims) > 0 {
+		d.nextAnimationFrame()
+	}
+}
+
+// stopAnimations ends the animations and their pending frame.
+func (d *Desktop) stopAnimations() {
+	if d.animTimer != nil {
+		d.animTimer.Stop()
+		d.animTimer = nil
+	}
+	d.anims = nil
+}
+
+func (d *Desktop) isAnimating(win *Window) bool {
+	for _, anim := range d.anims {
+		if anim.win == win {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Desktop) drawAnimations(screen tcell.Screen) {
+	if len(d.anims) == 0 {
+		return
+	}
+	theme := d.winMgr.GetTheme().ForColors(d.colors)
+	style := tcell.StyleDefault.Foreground(theme.ActiveBorderColor).Bold(theme.Monochrome)
+	for _, anim := range d.anims {
+		lerp := func(from, to int) int {
+			return from + int(float64(to-from)*anim.progress)
+		}
+		drawOutline(screen, lerp(anim.fromX, anim.toX), lerp(anim.fromY, anim.toY),
+			lerp(anim.fromW, anim.toW), lerp(anim.fromH, anim.toH), style)
+	}
+}
+
+// drawOutline draws a rectangle outline over what's on the screen.
+func drawOutline(screen tcell.Screen, x, y, w, h int, style tcell.Style) {
+	set := func(i, j int, c rune) {
+		_, _, oldStyle, _ := screen.GetContent(i, j)
+		_, bg, _ := oldStyle.Decompose()
+		screen.SetContent(i, j, c, nil, style.Background(bg))
+	}
+	if w < 1 || h < 1 {
+		return
+	}
+	if w == 1 || h == 1 {
+		for j := y; j < y+h; j++ {
+			for i := x; i < x+w; i++ {
+				set(i, j, '▪')
+			}
+		}
+		return
+	}
+	for i := x + 1; i < x+w-1; i++ {
+		set(i, y, SingleBorder.Horizontal)
+		set(i, y+h-1, SingleBorder.Horizontal)
+	}
+	for j := y + 1; j < y+h-1; j++ {
+		set(x, j, SingleBorder.Vertical)
+		set(x+w-1, j, SingleBorder.Vertical)
+	}
+	set(x, y, SingleBorder.TopLeft)
+	set(x+w-1, y, SingleBorder.TopRight)
+	set(x, y+h-1, SingleBorder.BottomLeft)
+	set(x+w-1, y+h-1, SingleBorder.BottomRight)
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+func TestReduceMotion(t *testing.T) {
+	screen := simScreen(t, 40, 12)
+	d := tuix.NewDesktop()
+	app := tview.NewApplication().SetScreen(screen).SetRoot(d, true)
+	d.SetApplication(app).SetAnimation(&tuix.AnimationOptions{Duration: time.Hour})
+	win := tuix.NewWindow()
+	win.SetTitle("Hello").SetBorder(true).SetRect(2, 2, 20, 8)
+	d.AddWindow(win)
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+	defer func() {
+		app.Stop()
+		<-done
+	}()
+	defer tuix.SetReduceMotion(false)
+	shown := func() bool {
+		var text string
+		app.QueueUpdate(func() { text = tuix.ScreenSnapshot(screen, 0, 0, 40, 12).Text() })
+		return strings.Contains(text, "Hello")
+	}
+	waitShown := func() bool {
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+			if shown() {
+				return true
+			}
+		}
+		return false
+	}
+	if !waitShown() {
+		t.Fatal("window is not shown")
+	}
+
+	app.QueueUpdateDraw(func() { win.SetState(tuix.Maximized) })
+	if shown() {
+		t.Error("window is shown while animating")
+	}
+	// Set from another goroutine than the animation's timer.
+	tuix.SetReduceMotion(true)
+	if !waitShown() {
+		t.Error("animation did not stop when reducing motion")
+	}
+
+	app.QueueUpdateDraw(func() { win.SetState(tuix.Restored) })
+	if !shown() {
+		t.Error("window is animated while reducing motion")
+	}
+}
+
+func TestAnimationAfterAppStops(t *testing.T) {
+	d := tuix.NewDesktop()
+	d.SetAnimation(&tuix.AnimationOptions{Duration: time.Hour, FrameRate: 20})
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(2, 2, 20, 8)
+	d.AddWindow(win)
+	stoppedApp(t, d, func(screen tcell.Screen) {
+		d.SetRect(0, 0, 40, 12)
+		d.Draw(screen)
+		win.SetState(tuix.Maximized)
+	})
+	d.SetApplication(nil)
+	assertNoBlockedTimers(t, 150*time.Millisecond)
+}
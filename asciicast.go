@@ -0,0 +1,188 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// AsciicastScreen is a screen which records what it shows as an asciicast v2 recording,
+// which can be played with asciinema. Each Show records the cells which changed,
+// and a size change, such as when the terminal is resized, records a resize.
+//
+//	rec := tuix.NewAsciicastScreen(screen, f)
+//	app.SetScreen(rec)
+type AsciicastScreen struct {
+	tcell.Screen
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	prev    *Snapshot
+	cursorX int
+	cursorY int // Negative if hidden.
+	shownX  int // The cursor last recorded.
+	shownY  int
+	err     error
+	now     func() time.Time
+}
+
+// NewAsciicastScreen creates a screen which shows on the screen and records to w.
+// The header is written on the first Show, when the size is known.
+func NewAsciicastScreen(screen tcell.Screen, w io.Writer) *AsciicastScreen {
+	return &AsciicastScreen{
+		Screen:  screen,
+		w:       w,
+		start:   time.Now(),
+		cursorX: -1,
+		cursorY: -1,
+		now:     time.Now,
+	}
+}
+
+// Err gets the first error writing the recording, recording stops after an error.
+func (s *AsciicastScreen) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// ShowCursor shows the cursor, which is recorded at the next Show.
+func (s *AsciicastScreen) ShowCursor(x, y int) {
+	s.mu.Lock()
+	s.cursorX, s.cursorY = x, y
+	s.mu.Unlock()
+	s.Screen.ShowCursor(x, y)
+}
+
+// HideCursor hides the cursor, which is recorded at the next Show.
+func (s *AsciicastScreen) HideCursor() {
+	s.mu.Lock()
+	s.cursorX, s.cursorY = -1, -1
+	s.mu.Unlock()
+	s.Screen.HideCursor()
+}
+
+// Show shows and records the changes.
+func (s *AsciicastScreen) Show() {
+	s.Screen.Show()
+	s.record(false)
+}
+
+// Sync shows and records the whole screen.
+func (s *AsciicastScreen) Sync() {
+	s.Screen.Sync()
+	s.record(true)
+}
+
+func (s *AsciicastScreen) write(v interface{}) {
+	if s.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err == nil {
+		_, err = s.w.Write(append(b, '\n'))
+	}
+	s.err = err
+}
+
+func (s *AsciicastScreen) record(full bool) {
+	w, h := s.Screen.Size()
+	snap := ScreenSnapshot(s.Screen, 0, 0, w, h)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	t := float64(s.now().Sub(s.start)) / float64(time.Second)
+	prev := s.prev
+	s.prev = snap
+	if prev == nil {
+		s.write(asciicastHeader{
+			Version:   2,
+			Width:     w,
+			Height:    h,
+			Timestamp: s.start.Unix(),
+			Env:       map[string]string{"TERM": "xterm-256color"},
+		})
+	} else if prev.Width != w || prev.Height != h {
+		s.write([]interface{}{t, "r", fmt.Sprintf("%dx%d", w, h)})
+		full = true
+	}
+	if prev == nil {
+		full = true
+	}
+
+	var sb strings.Builder
+	if full {
+		sb.WriteString("\x1b[0m\x1b[2J")
+	}
+	atX, atY := -1, -1
+	var style tcell.Style
+	styled := false
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cell := snap.Cells[y*w+x]
+			if cell.Rune == 0 {
+				continue // Covered by a wide rune.
+			}
+			if !full && sameCell(cell, prev.Cells[y*w+x]) {
+				continue
+			}
+			if x != atX || y != atY {
+				fmt.Fprintf(&sb, "\x1b[%d;%dH", y+1, x+1)
+			}
+			if !styled || cell.Style != style {
+				sb.WriteString(ansiStyle(cell.Style))
+				style, styled = cell.Style, true
+			}
+			sb.WriteRune(cell.Rune)
+			for _, r := range cell.Comb {
+				sb.WriteRune(r)
+			}
+			atX, atY = x+1, y
+			for atX < w && snap.Cells[y*w+atX].Rune == 0 {
+				atX++
+			}
+		}
+	}
+	cursorX, cursorY := s.cursorX, s.cursorY
+	if cursorX >= w || cursorY >= h {
+		cursorX, cursorY = -1, -1
+	}
+	if sb.Len() == 0 && cursorX == s.shownX && cursorY == s.shownY {
+		return
+	}
+	s.shownX, s.shownY = cursorX, cursorY
+	if styled {
+		sb.WriteString("\x1b[0m")
+	}
+	if cursorX >= 0 && cursorY >= 0 {
+		fmt.Fprintf(&sb, "\x1b[%d;%dH\x1b[?25h", cursorY+1, cursorX+1)
+	} else {
+		sb.WriteString("\x1b[?25l")
+	}
+	s.write([]interface{}{t, "o", sb.String()})
+}
+
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env"`
+}
+
+func sameCell(a, b SnapshotCell) bool {
+	return a.Rune == b.Rune && a.Style == b.Style && string(a.Comb) == string(b.Comb)
+}
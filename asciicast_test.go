@@ -0,0 +1,76 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+)
+
+// asciicastEvents reads the recorded events, then resets the recording.
+func asciicastEvents(t *testing.T, buf *bytes.Buffer) (events [][]interface{}) {
+	t.Helper()
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var ev []interface{}
+		if err := json.Unmarshal([]byte(line), &ev); err != nil || len(ev) != 3 {
+			t.Fatalf("event %q is not [time, type, data]", line)
+		}
+		events = append(events, ev)
+	}
+	buf.Reset()
+	return
+}
+
+func TestAsciicastScreen(t *testing.T) {
+	sim := simScreen(t, 4, 2)
+	buf := &bytes.Buffer{}
+	screen := tuix.NewAsciicastScreen(sim, buf)
+	defer screen.Fini()
+	screen.SetContent(0, 0, 'h', nil, tcell.StyleDefault)
+	screen.SetContent(1, 0, 'i', nil, tcell.StyleDefault.Bold(true))
+	screen.Show()
+	var header map[string]interface{}
+	headerLine, _ := buf.ReadBytes('\n')
+	if err := json.Unmarshal(headerLine, &header); err != nil {
+		t.Fatal(err)
+	}
+	events := asciicastEvents(t, buf)
+	if header["version"] != 2.0 || header["width"] != 4.0 || header["height"] != 2.0 {
+		t.Errorf("header is %v", header)
+	}
+	want := "\x1b[0m\x1b[2J\x1b[1;1H\x1b[0mh\x1b[0;1mi\x1b[0m  \x1b[2;1H    \x1b[0m\x1b[?25l"
+	if len(events) != 1 || events[0][1] != "o" || events[0][2] != want {
+		t.Errorf("first show recorded %q, expected %q", events, want)
+	}
+
+	screen.Show() // Nothing changed.
+	screen.SetContent(1, 0, 'o', nil, tcell.StyleDefault)
+	screen.ShowCursor(2, 1)
+	screen.Show()
+	events = asciicastEvents(t, buf)
+	want = "\x1b[1;2H\x1b[0mo\x1b[0m\x1b[2;3H\x1b[?25h"
+	if len(events) != 1 || events[0][2] != want {
+		t.Errorf("change recorded %q, expected %q", events, want)
+	}
+
+	sim.SetSize(5, 2)
+	screen.Show()
+	events = asciicastEvents(t, buf)
+	if len(events) != 2 || events[0][1] != "r" || events[0][2] != "5x2" {
+		t.Errorf("resize recorded %q", events)
+	} else if out := events[1][2].(string); !strings.HasPrefix(out, "\x1b[0m\x1b[2J") {
+		t.Errorf("resize did not record the whole screen: %q", out)
+	}
+	if err := screen.Err(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// ClipScreen wraps a screen so that drawing only changes cells in the rect,
+// everything outside it is left alone. Coordinates are not translated,
+// and reading the screen is not limited.
+// A wide rune which would spill past the right edge is drawn as a space.
+// The window manager draws window clients through one of these,
+// so that clients cannot draw over other windows.
+func ClipScreen(screen tcell.Screen, x, y, width, height int) tcell.Screen {
+	dx, dy := 0, 0
+	if cs, ok := screen.(*clipScreen); ok {
+		// Intersect with the existing clip instead of nesting.
+		x2, y2 := x+width, y+height
+		if x < cs.x {
+			x = cs.x
+		}
+		if y < cs.y {
+			y = cs.y
+		}
+		if x2 > cs.x+cs.width {
+			x2 = cs.x + cs.width
+		}
+		if y2 > cs.y+cs.height {
+			y2 = cs.y + cs.height
+		}
+		screen, width, height = cs.Screen, x2-x, y2-y
+		dx, dy = cs.dx, cs.dy
+	}
+	return &clipScreen{Screen: screen, x: x, y: y, width: width, height: height, dx: dx, dy: dy}
+}
+
+// offsetScreen wraps a screen so that drawing at x, y is at x+dx, y+dy on the screen,
+// and only in the rect, which is in the wrapper's coordinates.
+// The desktop draws the windows of a virtual desktop through one of these.
+func offsetScreen(screen tcell.Screen, x, y, width, height, dx, dy int) tcell.Screen {
+	return &clipScreen{Screen: screen, x: x, y: y, width: width, height: height, dx: dx, dy: dy}
+}
+
+type clipScreen struct {
+	tcell.Screen
+	x, y, width, height int
+	dx, dy              int // Added to coordinates for the screen, see offsetScreen.
+}
+
+func (cs *clipScreen) inClip(x, y int) bool {
+	return x >= cs.x && y >= cs.y && x < cs.x+cs.width && y < cs.y+cs.height
+}
+
+// fits returns true if the rune at x doesn't spill past the right edge of the clip;
+// a wide rune in the last column would cover a cell outside.
+func (cs *clipScreen) fits(x int, c rune) bool {
+	return x < cs.x+cs.width-1 || runewidth.RuneWidth(c) < 2
+}
+
+func (cs *clipScreen) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	if cs.inClip(x, y) {
+		if !cs.fits(x, mainc) {
+			mainc, combc = ' ', nil
+		}
+		cs.Screen.SetContent(x+cs.dx, y+cs.dy, mainc, combc, style)
+	}
+}
+
+func (cs *clipScreen) SetCell(x, y int, style tcell.Style, ch ...rune) {
+	if cs.inClip(x, y) {
+		if len(ch) > 0 && !cs.fits(x, ch[0]) {
+			ch = []rune{' '}
+		}
+		cs.Screen.SetCell(x+cs.dx, y+cs.dy, style, ch...)
+	}
+}
+
+func (cs *clipScreen) GetContent(x, y int) (mainc rune, combc []rune, style tcell.Style, width int) {
+	return cs.Screen.GetContent(x+cs.dx, y+cs.dy)
+}
+
+// Size gets the size of the screen in the wrapper's coordinates,
+// so it covers the screen even when the coordinates are offset.
+func (cs *clipScreen) Size() (int, int) {
+	w, h := cs.Screen.Size()
+	return w - cs.dx, h - cs.dy
+}
+
+func (cs *clipScreen) Fill(c rune, style tcell.Style) {
+	for j := cs.y; j < cs.y+cs.height; j++ {
+		for i := cs.x; i < cs.x+cs.width; i++ {
+			cs.Screen.SetContent(i+cs.dx, j+cs.dy, c, nil, style)
+		}
+	}
+}
+
+func (cs *clipScreen) Clear() {
+	cs.Fill(' ', tcell.StyleDefault)
+}
+
+func (cs *clipScreen) ShowCursor(x, y int) {
+	if cs.inClip(x, y) {
+		cs.Screen.ShowCursor(x+cs.dx, y+cs.dy)
+	} else {
+		cs.Screen.HideCursor()
+	}
+}
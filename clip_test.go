@@ -0,0 +1,104 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+func assertRunes(t *testing.T, screen tcell.Screen, y int, want string) {
+	t.Helper()
+	got := make([]rune, 0, len(want))
+	for x := range []rune(want) {
+		c, _, _, _ := screen.GetContent(x, y)
+		got = append(got, c)
+	}
+	if string(got) != want {
+		t.Errorf("line %d is %q, expected %q", y, string(got), want)
+	}
+}
+
+func TestClipScreen(t *testing.T) {
+	screen := simScreen(t, 10, 3)
+	defer screen.Fini()
+	screen.Fill('.', tcell.StyleDefault)
+	cs := tuix.ClipScreen(screen, 2, 0, 4, 2)
+	for x := 0; x < 10; x++ {
+		cs.SetContent(x, 0, 'a', nil, tcell.StyleDefault)
+		cs.SetCell(x, 2, tcell.StyleDefault, 'b')
+	}
+	assertRunes(t, screen, 0, "..aaaa....")
+	assertRunes(t, screen, 2, "..........")
+
+	cs.SetContent(2, 1, '界', nil, tcell.StyleDefault)
+	cs.SetCell(5, 1, tcell.StyleDefault, '界')
+	if c, _, _, width := screen.GetContent(2, 1); c != '界' || width != 2 {
+		t.Errorf("wide rune in the clip is %q, width %d", c, width)
+	}
+	assertRunes(t, screen, 1, "..界.. ....") // The second wide rune would spill past the clip.
+	if c, _, _, _ := screen.GetContent(6, 1); c != '.' {
+		t.Errorf("cell right of the clip is %q", c)
+	}
+
+	if c, _, _, _ := cs.GetContent(8, 0); c != '.' {
+		t.Errorf("reading outside the clip gets %q", c)
+	}
+	if w, h := cs.Size(); w != 10 || h != 3 {
+		t.Errorf("size is %dx%d, expected the screen's", w, h)
+	}
+	cs.Fill('f', tcell.StyleDefault)
+	assertRunes(t, screen, 1, "..ffff....")
+	assertRunes(t, screen, 2, "..........")
+}
+
+func TestClipScreenNested(t *testing.T) {
+	screen := simScreen(t, 10, 4)
+	defer screen.Fini()
+	screen.Fill('.', tcell.StyleDefault)
+	outer := tuix.ClipScreen(screen, 2, 1, 4, 2)
+	inner := tuix.ClipScreen(outer, 4, 0, 10, 10) // Only (4, 1) to (5, 2) is in both.
+	inner.Fill('x', tcell.StyleDefault)
+	assertRunes(t, screen, 0, "..........")
+	assertRunes(t, screen, 1, "....xx....")
+	assertRunes(t, screen, 2, "....xx....")
+	assertRunes(t, screen, 3, "..........")
+	inner.SetContent(5, 1, '界', nil, tcell.StyleDefault)
+	assertRunes(t, screen, 1, "....x ....")
+}
+
+func TestClipScreenOffset(t *testing.T) {
+	h := tuixtest.New(t, 30, 12)
+	defer h.Close()
+	h.Desktop.SetVirtualSize(100, 50)
+	client := tview.NewBox()
+	client.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		for j := y - 1; j <= y+height; j++ {
+			for i := x - 1; i <= x+width; i++ {
+				screen.SetContent(i, j, 'c', nil, tcell.StyleDefault)
+			}
+		}
+		screen.SetContent(x+width-1, y+1, '界', nil, tcell.StyleDefault)
+		return x, y, width, height
+	})
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(12, 7, 12, 5)
+	win.SetClient(client, true)
+	h.Desktop.AddWindow(win)
+	h.Desktop.ScrollTo(10, 5)
+	h.Draw()
+	// The window is at (2, 2) on the screen, its client at (3, 3) 10x3.
+	h.AssertText(2, 2, "┌──────────┐")
+	h.AssertText(2, 3, "│cccccccccc│")
+	h.AssertText(2, 4, "│ccccccccc │")
+	h.AssertText(2, 5, "│cccccccccc│")
+	h.AssertText(2, 6, "└──────────┘")
+}
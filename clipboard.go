@@ -0,0 +1,229 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Clipboard holds text copied by the desktop's windows, see Desktop.Clipboard.
+// It's safe to use from any goroutine.
+type Clipboard struct {
+	mu      sync.Mutex
+	text    string
+	osc52   io.Writer
+	changed func(text string)
+}
+
+// Set sets the text of the clipboard, and copies it to the host terminal if enabled by SetOSC52.
+func (c *Clipboard) Set(text string) {
+	c.mu.Lock()
+	c.text = text
+	w := c.osc52
+	changed := c.changed
+	c.mu.Unlock()
+	if w != nil {
+		io.WriteString(w, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte(text))+"\x07")
+	}
+	if changed != nil {
+		changed(text)
+	}
+}
+
+// Get gets the text of the clipboard.
+func (c *Clipboard) Get() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.text
+}
+
+// SetOSC52 sets where to write an OSC 52 escape sequence when the clipboard is set,
+// which copies to the clipboard of the host terminal, if the terminal supports it;
+// usually os.Stdout, or the session for sshd. Nil disables it.
+// Only set the clipboard from the application's goroutine when it's the same terminal,
+// so the sequence isn't written in the middle of drawing.
+func (c *Clipboard) SetOSC52(w io.Writer) *Clipboard {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.osc52 = w
+	return c
+}
+
+// SetChangedFunc sets a func called when the clipboard is set.
+func (c *Clipboard) SetChangedFunc(changed func(text string)) *Clipboard {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changed = changed
+	return c
+}
+
+// Clipboard gets the desktop's clipboard, shared by its windows.
+func (d *Desktop) Clipboard() *Clipboard {
+	if d.clipboard == nil {
+		d.clipboard = &Clipboard{}
+	}
+	return d.clipboard
+}
+
+// Paster can be implemented by primitives which take pasted text as a whole,
+// such as terminal.Terminal; see Desktop.Paste.
+type Paster interface {
+	Paste(text string)
+}
+
+// Paste pastes the text into the focused window: to the focused primitive if it's a Paster,
+// otherwise as key presses.
+func (d *Desktop) Paste(text string) {
+	var focused tview.Primitive
+	for _, win := range d.wins {
+		if win.HasFocus() {
+			focused = win
+		}
+	}
+	if focused == nil && d.client != nil && d.client.HasFocus() {
+		focused = d.client
+	}
+	if focused == nil {
+		return
+	}
+	if p := findPaster(focused); p != nil {
+		p.Paste(text)
+		return
+	}
+	handler := d.InputHandler()
+	setFocus := func(p tview.Primitive) {
+		if d.app != nil {
+			d.app.SetFocus(p)
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), setFocus)
+		case r == '\t':
+			handler(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), setFocus)
+		case r >= ' ' && r != 0x7f:
+			handler(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), setFocus)
+		}
+	}
+}
+
+// PasteClipboard pastes the clipboard's text into the focused window.
+func (d *Desktop) PasteClipboard() {
+	d.Paste(d.Clipboard().Get())
+}
+
+// findPaster finds the focused Paster in p, through primitives with a GetChildren method.
+func findPaster(p tview.Primitive) Paster {
+	if parent, ok := p.(interface{ GetChildren() []tview.Primitive }); ok {
+		for _, child := range parent.GetChildren() {
+			if child.HasFocus() {
+				if paster := findPaster(child); paster != nil {
+					return paster
+				}
+			}
+		}
+	}
+	if paster, ok := p.(Paster); ok {
+		return paster
+	}
+	return nil
+}
+
+// pasteCollector collects the keys between the start and end of a bracketed paste.
+type pasteCollector struct {
+	pasting bool
+	cr      bool // The last key was a CR, so a LF after it is the same newline.
+	buf     strings.Builder
+}
+
+// collect returns true if the event is part of a paste, and the text when the paste ends.
+func (pc *pasteCollector) collect(event tcell.Event) (consumed bool, text string, done bool) {
+	switch event := event.(type) {
+	case *tcell.EventPaste:
+		if event.Start() {
+			pc.pasting = true
+			pc.cr = false
+			pc.buf.Reset()
+			return true, "", false
+		}
+		pc.pasting = false
+		text = pc.buf.String()
+		pc.buf.Reset()
+		return true, text, true
+	case *tcell.EventKey:
+		if !pc.pasting {
+			return false, "", false
+		}
+		cr := pc.cr
+		pc.cr = false
+		switch event.Key() {
+		case tcell.KeyRune:
+			pc.buf.WriteRune(event.Rune())
+		case tcell.KeyCR: // Also KeyEnter.
+			pc.buf.WriteByte('\n')
+			pc.cr = true
+		case tcell.KeyLF: // Comes with ModCtrl, as Ctrl+J.
+			if !cr {
+				pc.buf.WriteByte('\n')
+			}
+		case tcell.KeyTab:
+			pc.buf.WriteByte('\t')
+		}
+		return true, "", false
+	}
+	return false, "", false
+}
+
+// PasteScreen is a screen which sends bracketed pastes to a desktop's Paste,
+// instead of as key presses. Use it as the application's screen:
+//
+//	app.SetScreen(tuix.NewPasteScreen(screen, desktop))
+type PasteScreen struct {
+	tcell.Screen
+	desktop *Desktop
+	pc      pasteCollector
+}
+
+// NewPasteScreen creates a screen which pastes into the desktop.
+// It enables bracketed paste when initialized; if the screen is already initialized, call EnablePaste.
+func NewPasteScreen(screen tcell.Screen, d *Desktop) *PasteScreen {
+	return &PasteScreen{Screen: screen, desktop: d}
+}
+
+func (s *PasteScreen) Init() error {
+	if err := s.Screen.Init(); err != nil {
+		return err
+	}
+	s.Screen.EnablePaste()
+	return nil
+}
+
+// PollEvent gets the next event which isn't part of a paste.
+func (s *PasteScreen) PollEvent() tcell.Event {
+	for {
+		event := s.Screen.PollEvent()
+		consumed, text, done := s.pc.collect(event)
+		if !consumed {
+			return event
+		}
+		if done && text != "" {
+			d := s.desktop
+			if d.app != nil {
+				d.app.QueueUpdateDraw(func() { d.Paste(text) })
+			} else {
+				d.Paste(text)
+			}
+		}
+	}
+}
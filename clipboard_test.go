@@ -0,0 +1,66 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+// paster keeps what's pasted into it.
+type paster struct {
+	*tview.Box
+	pasted []string
+}
+
+func (p *paster) Paste(text string) {
+	p.pasted = append(p.pasted, text)
+}
+
+func TestPasteScreenNewlines(t *testing.T) {
+	h := tuixtest.New(t, 40, 12)
+	defer h.Close()
+	client := &paster{Box: tview.NewBox()}
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(2, 2, 20, 8)
+	win.SetClient(client, true)
+	h.Desktop.AddWindow(win)
+	h.SetFocus(win)
+
+	sim := simScreen(t, 40, 12)
+	screen := tuix.NewPasteScreen(sim, h.Desktop)
+	defer screen.Fini()
+	go func() { // More events than the screen queues.
+		key := func(k tcell.Key, r rune, mod tcell.ModMask) {
+			sim.PostEventWait(tcell.NewEventKey(k, r, mod))
+		}
+		sim.PostEventWait(tcell.NewEventPaste(true))
+		key(tcell.KeyRune, 'a', 0)
+		key(tcell.KeyCR, 0, 0)
+		key(tcell.KeyRune, 'b', 0)
+		key(tcell.KeyLF, 0, tcell.ModCtrl)
+		key(tcell.KeyRune, 'c', 0)
+		key(tcell.KeyCR, 0, 0)
+		key(tcell.KeyLF, 0, tcell.ModCtrl)
+		key(tcell.KeyTab, 0, 0)
+		key(tcell.KeyRune, 'd', 0)
+		sim.PostEventWait(tcell.NewEventPaste(false))
+		key(tcell.KeyRune, 'z', 0)
+	}()
+	event, ok := screen.PollEvent().(*tcell.EventKey)
+	if !ok || event.Rune() != 'z' {
+		t.Errorf("event after the paste is %v", event)
+	}
+	want := "a\nb\nc\n\td"
+	if len(client.pasted) != 1 || client.pasted[0] != want {
+		t.Errorf("pasted %q, expected %q", client.pasted, want)
+	}
+}
@@ -3,11 +3,15 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/record"
+	"github.com/millerlogic/tuix/terminal"
 	"github.com/rivo/tview"
 )
 
@@ -25,7 +29,17 @@ func run() error {
 		return err
 	}
 	screen.EnableMouse()
-	app.SetScreen(screen)
+	screen.EnablePaste()
+	appScreen := screen
+	if *castFile != "" {
+		f, err := os.Create(*castFile)
+		if err != nil {
+			screen.Fini()
+			return err
+		}
+		defer f.Close()
+		appScreen = tuix.NewAsciicastScreen(screen, f)
+	}
 
 	win := tuix.NewWindow().SetAutoPosition(true)
 	win.SetTitle("Window 1")
@@ -61,12 +75,105 @@ func run() error {
 	tv.SetBorderPadding(1, 1, 1, 1)
 	win2.SetClient(tv, true)
 
-	desktop := tuix.NewDesktop()
+	desktop := tuix.NewDesktop().SetApplication(app)
+	app.SetScreen(tuix.NewPasteScreen(appScreen, desktop))
+	desktop.Clipboard().SetOSC52(os.Stdout)
+	desktop.SetAnimation(&tuix.AnimationOptions{})
 	//desktop.SetTitle("Desktop").SetBorder(true)
 	desktop.AddWindow(win).AddWindow(win2)
 
+	setFocus := func(p tview.Primitive) { app.SetFocus(p) }
+	nwins := 2
+	icons := tuix.NewDesktopIcons()
+	icons.AddIcon("[#]", "Window 1", func() {
+		win.SetState(tuix.Restored).Activate(setFocus)
+	})
+	icons.AddIcon("[#]", "Window 2", func() {
+		win2.SetState(tuix.Restored).Activate(setFocus)
+	})
+	icons.AddIcon("[+]", "New Window", func() {
+		nwins++
+		xwin := tuix.NewWindow().SetAutoPosition(true).SetResizable(true).SetClosable(true)
+		xwin.SetTitle(fmt.Sprintf("Window %d", nwins))
+		xwin.SetBorder(true).SetRect(0, 0, 30, 10)
+		desktop.AddWindow(xwin)
+		xwin.Activate(setFocus)
+	})
+	icons.AddIcon("[>]", "Terminal", func() {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		xwin := tuix.NewWindow().SetAutoPosition(true).SetResizable(true).SetClosable(true)
+		xwin.SetTitle("Terminal")
+		xwin.SetBorder(true).SetRect(0, 0, 60, 16)
+		term := terminal.NewTerminal()
+		term.SetChangedFunc(func() {
+			app.QueueUpdateDraw(func() {
+				if title := term.GetTitle(); title != "" && title != xwin.GetTitle() {
+					xwin.SetTitle(title)
+				}
+			})
+		})
+		closed := false
+		xwin.SetCloseFunc(func() {
+			closed = true
+			term.Close() // Hangs up the shell.
+		})
+		term.SetDoneFunc(func(err error) {
+			app.QueueUpdateDraw(func() {
+				if closed {
+					return
+				}
+				xwin.Close()
+				setFocus(desktop)
+				if err != nil {
+					desktop.Notify("Terminal", err.Error(), &tuix.NotifyOptions{Severity: tuix.NotifyError})
+				} else {
+					desktop.Notify("Terminal", "The shell exited.", nil)
+				}
+			})
+		})
+		xwin.SetClient(term, true)
+		desktop.AddWindow(xwin)
+		if err := term.Start(exec.Command(shell)); err != nil {
+			fmt.Fprintf(term, "Unable to start %s: %v", shell, err)
+		}
+		xwin.Activate(setFocus)
+	})
+	desktop.SetClient(icons, true)
+
 	app.SetRoot(desktop, true)
 
+	if *recordFile != "" {
+		f, err := os.Create(*recordFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		rec := record.NewRecorder(desktop, f)
+		app.SetRoot(rec, true)
+		defer func() {
+			if err := rec.Err(); err != nil {
+				fmt.Fprintf(os.Stderr, "Unable to record: %v\n", err)
+			}
+		}()
+	}
+	if *replayFile != "" {
+		f, err := os.Open(*replayFile)
+		if err != nil {
+			return err
+		}
+		events, err := record.ReadEvents(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		rep := record.NewReplayer(desktop, events)
+		go rep.Play(app)
+		defer rep.Stop()
+	}
+
 	if err := app.Run(); err != nil {
 		return err
 	}
@@ -74,7 +181,12 @@ func run() error {
 	return nil
 }
 
+var recordFile = flag.String("record", "", "Record the input to a file")
+var replayFile = flag.String("replay", "", "Replay the input from a file")
+var castFile = flag.String("asciicast", "", "Record the screen to an asciicast file")
+
 func main() {
+	flag.Parse()
 	err := run()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
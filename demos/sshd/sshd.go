@@ -0,0 +1,81 @@
+// This demo is public domain, or MPLv2 if you prefer.
+
+// Serves a desktop over SSH, try: ssh -p 2222 you@localhost
+// With -shared, everyone who connects uses the same desktop.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/sshd"
+	"github.com/rivo/tview"
+	"golang.org/x/crypto/ssh"
+)
+
+func run() error {
+	// A new host key each run, a real server would load one.
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return err
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	var srv *sshd.Server
+	if *shared {
+		desktop := tuix.NewDesktop()
+		win := tuix.NewWindow().SetAutoPosition(true).SetResizable(true)
+		win.SetTitle("Shared")
+		win.SetBorder(true).SetRect(0, 0, 40, 10)
+		tv := tview.NewTextView().SetWordWrap(true)
+		fmt.Fprint(tv, "Everyone connected sees this desktop, and the others' mouse pointers.")
+		win.SetClient(tv, true)
+		desktop.AddWindow(win)
+		srv = sshd.NewSharedServer(config, tuix.NewSharedDesktop(desktop))
+	} else {
+		srv = sshd.NewServer(config, func(sess *sshd.Session) *tuix.Desktop {
+			desktop := tuix.NewDesktop()
+			win := tuix.NewWindow().SetAutoPosition(true).SetResizable(true)
+			win.SetTitle("Welcome")
+			win.SetBorder(true).SetRect(0, 0, 40, 10)
+			tv := tview.NewTextView().SetWordWrap(true)
+			fmt.Fprintf(tv, "Hello %s from %s, your terminal is %s.\n\nPress Q to disconnect.",
+				sess.User(), sess.RemoteAddr(), sess.Term())
+			tv.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+				if event.Rune() == 'q' || event.Rune() == 'Q' {
+					sess.Close()
+					return nil
+				}
+				return event
+			})
+			win.SetClient(tv, true)
+			desktop.AddWindow(win)
+			return desktop
+		})
+	}
+	srv.SetIdleTimeout(10 * time.Minute).SetMaxConnections(20)
+	fmt.Println("Listening on localhost:2222")
+	return srv.ListenAndServe("localhost:2222")
+}
+
+var shared = flag.Bool("shared", false, "Share one desktop with everyone")
+
+func main() {
+	flag.Parse()
+	err := run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -7,6 +7,8 @@
 package tuix
 
 import (
+	"time"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -14,21 +16,64 @@ import (
 // Desktop represents an area where windows go.
 type Desktop struct {
 	*tview.Box
-	wins           []*Window // in stack/draw order
+	*pointerState                  // The pointer whose events are being handled.
+	pointers       []*pointerState // Every pointer, see SharedDesktop.
+	wins           []*Window       // in stack/draw order
 	winMgr         WindowManager
 	client         tview.Primitive
+	app            *tview.Application
+	animOpts       *AnimationOptions
+	anims          []*windowAnimation
+	animTimer      *time.Timer // Next animation frame.
 	autoWinPos     int
+	colors         int  // Screen colors, see WindowTheme.ForColors.
+	offscreen      bool // Drawing a Snapshot, see Snapshot.
 	init           bool
 	clientFullSize bool
+	outlineDrag    bool
+	covered        []bool // Screen cells covered by windows, see findHidden.
+	hidden         []bool // Windows which don't need drawing, see findHidden.
+	virtW, virtH   int    // Virtual desktop size, see SetVirtualSize.
+	scrollX        int
+	scrollY        int
+	miniMap        bool
+	panKeys        bool                   // Alt+arrows pan the virtual desktop before windows get them.
+	movable        func(win *Window) bool // See mayMove.
+	focusFollows   bool
+	clipboard      *Clipboard
+	selLinearMod   tcell.ModMask
+	selRectMod     tcell.ModMask
+	notes          []*Notification // See Notify.
+	redraw         func()          // Draws without an app, see redrawFunc.
+}
+
+// pointerState is the state of a mouse pointer's drags, selection and hover.
+// A shared desktop has one for each session.
+type pointerState struct {
+	hover         *Window // Window under the mouse pointer.
+	drag          *Drag   // See BeginDrag.
+	sel           *selection
+	selecting     bool
+	panning       bool // Middle dragging the view.
+	panX, panY    int
+	scrollDrag    byte // Dragging a scrollbar thumb, 1=horiz, 2=vert.
+	scrollDragOff int
+	winCapture    tview.Primitive // In a window which captured the mouse, see windowMouse.
+	winCaptureWin *Window
 }
 
 // NewDesktop creates a new desktop, it needs to be added to an Application.
 func NewDesktop() *Desktop {
 	d := &Desktop{
-		Box:    tview.NewBox(),
-		winMgr: DefaultWindowManager,
+		Box:          tview.NewBox(),
+		winMgr:       DefaultWindowManager,
+		colors:       256, // Until drawn.
+		selLinearMod: tcell.ModShift,
+		selRectMod:   tcell.ModAlt,
 	}
-	d.SetBackgroundColor(tcell.ColorValid + 234)
+	d.pointerState = &pointerState{}
+	d.pointers = []*pointerState{d.pointerState}
+	d.SetBackgroundColor(tcell.ColorDefault) // Until set, the theme's DesktopColor is drawn by the window manager.
 	return d
 }
 
@@ -72,6 +117,7 @@ func (d *Desktop) RemoveWindow(win *Window) *Desktop {
 					app.SetFocus(d.wins[i-1])
 				}
 			}*/
+			d.leaveWindow(win)
 			d.winMgr.Removed(win)
 			break
 		}
@@ -95,6 +141,22 @@ func (d *Desktop) BottomWindow() *Window {
 	return nil
 }
 
+// GetWindows gets the windows, from the bottom to the top of the z-order.
+func (d *Desktop) GetWindows() []*Window {
+	return append([]*Window(nil), d.wins...)
+}
+
+// WindowAt gets the top window at the screen position, or nil.
+func (d *Desktop) WindowAt(x, y int) *Window {
+	for iwin := len(d.wins) - 1; iwin >= 0; iwin-- {
+		wx, wy, ww, wh := d.WindowToScreen(d.wins[iwin])
+		if x >= wx && y >= wy && x < wx+ww && y < wy+wh {
+			return d.wins[iwin]
+		}
+	}
+	return nil
+}
+
 // GetClient gets the client primitive previously set by SetClient, or nil.
 func (d *Desktop) GetClient() tview.Primitive {
 	return d.client
@@ -111,6 +173,32 @@ func (d *Desktop) SetClient(client tview.Primitive, fullSize bool) {
 	}
 }
 
+// GetApplication gets the application previously set by SetApplication, or nil.
+func (d *Desktop) GetApplication() *tview.Application {
+	return d.app
+}
+
+// SetApplication sets the application the desktop is in,
+// it's needed for things which happen over time, such as animations.
+// Set nil once the application stops, so pending timers don't wait on it forever.
+func (d *Desktop) SetApplication(app *tview.Application) *Desktop {
+	if app == d.app {
+		return d
+	}
+	d.stopAnimations()
+	d.app = app
+	d.restartNotifyTimers()
+	return d
+}
+
+// SetOutlineDrag determines if moving and resizing windows with the mouse
+// only draws an outline until the mouse is released.
+// This is much faster on slow connections, since the window is not redrawn.
+func (d *Desktop) SetOutlineDrag(on bool) *Desktop {
+	d.outlineDrag = on
+	return d
+}
+
 // SetWindowManager changes the WindowManager; see DefaultWindowManager
 func (d *Desktop) SetWindowManager(wm WindowManager) {
 	if d.winMgr == wm {
@@ -127,15 +215,40 @@ func (d *Desktop) SetWindowManager(wm WindowManager) {
 	d.winMgr = wm
 
 	for _, win := range d.wins {
+		win.SetTitleAlign(wm.GetTheme().TitleAlign)
 		wm.Added(win)
 	}
 }
 
+// GetTheme gets the theme of the desktop's window manager.
+func (d *Desktop) GetTheme() WindowTheme {
+	return d.winMgr.GetTheme()
+}
+
+// SetTheme changes the theme of the desktop's window manager,
+// the desktop and every window use it when next drawn.
+// The windows' title alignment is set to the theme's.
+// Note that DefaultWindowManager is shared by desktops, see NewWindowManager.
+func (d *Desktop) SetTheme(theme WindowTheme) *Desktop {
+	d.winMgr.SetTheme(theme)
+	for _, win := range d.wins {
+		win.SetTitleAlign(theme.TitleAlign)
+	}
+	return d
+}
+
+// mayMove returns true if the user may move, resize or change the state of the window
+// with the mouse; shared desktops decide this per session. d can be nil.
+func (d *Desktop) mayMove(win *Window) bool {
+	return d == nil || d.movable == nil || d.movable(win)
+}
+
 func (d *Desktop) SetRect(x, y, width, height int) {
 	d.Box.SetRect(x, y, width, height)
 	if d.client != nil && d.clientFullSize {
 		d.client.SetRect(d.GetInnerRect())
 	}
+	d.ScrollTo(d.scrollX, d.scrollY) // Keep it in the virtual desktop.
 	d.winMgr.DesktopResized(d)
 }
 
@@ -166,28 +279,189 @@ func (d *Desktop) HasFocus() bool {
 	return d.Box.HasFocus()
 }
 
+// Draw draws the desktop and its windows.
+// Windows which are fully covered by opaque windows above them are not drawn,
+// nor is the desktop background and client if windows cover all of it.
+// Windows are opaque if they have a background color, their own or the theme's.
+// Everything else is drawn every frame; tcell only sends changed cells to the terminal.
+// Windows of a virtual desktop are clipped to the view, see SetVirtualSize.
 func (d *Desktop) Draw(screen tcell.Screen) {
-	//d.Box.Draw(screen)
-	d.Box.DrawForSubclass(screen, d)
-	d.winMgr.DesktopDraw(d, screen)
-	if d.client != nil {
-		d.client.Draw(screen)
+	if !d.offscreen {
+		d.colors = screenColors(screen)
 	}
 	init := d.init
 	d.init = true
 	if !init {
 		d.winMgr.DesktopResized(d)
+		for _, win := range d.wins {
+			win.InitWindow()
+		}
+	}
+	virtual := d.isVirtual()
+	viewScreen := screen
+	if virtual {
+		vx, vy, vw, vh := d.GetViewRect()
+		viewScreen = ClipScreen(screen, vx, vy, vw, vh)
+	}
+	desktopHidden := d.findHidden(screen)
+	//d.Box.Draw(screen)
+	d.Box.DrawForSubclass(screen, d)
+	if !desktopHidden {
+		d.winMgr.DesktopDraw(d, screen)
+		if d.client != nil {
+			d.client.Draw(screen)
+		}
+	}
+	for i, win := range d.wins {
+		if !d.hidden[i] {
+			winScreen := d.windowScreen(screen, win)
+			win.Draw(winScreen)
+			if d.sel != nil && d.sel.win == win {
+				d.drawSelection(winScreen) // Under the windows above.
+			}
+		}
+	}
+	d.drawAnimations(viewScreen)
+	d.drawOutlineDrags(screen)
+	if !d.offscreen {
+		d.expireNotifications()
+	}
+	if virtual || d.drag != nil || len(d.notes) != 0 {
+		theme := d.winMgr.GetTheme().ForColors(d.colors)
+		if virtual {
+			d.drawScrollbars(screen, &theme)
+			if d.miniMap {
+				d.drawMiniMap(screen, &theme)
+			}
+		}
+		if len(d.notes) != 0 {
+			d.drawNotifications(screen, &theme)
+		}
+		if d.drag != nil {
+			d.drawDrag(screen, &theme)
+		}
+	}
+}
+
+// findHidden sets d.hidden for the windows which don't need drawing,
+// because they're animating, off the screen, or fully covered by opaque windows above them,
+// along with their shadows.
+// Returns true if the desktop's view is fully covered by opaque windows.
+func (d *Desktop) findHidden(screen tcell.Screen) (desktopHidden bool) {
+	sw, sh := screen.Size()
+	vx, vy, vw, vh := d.GetViewRect()
+	if cap(d.covered) < sw*sh {
+		d.covered = make([]bool, sw*sh)
+	}
+	covered := d.covered[:sw*sh]
+	for i := range covered {
+		covered[i] = false
+	}
+	if cap(d.hidden) < len(d.wins) {
+		d.hidden = make([]bool, len(d.wins))
+	}
+	d.hidden = d.hidden[:len(d.wins)]
+
+	// Calls f for each cell of the rect which is on the screen and in the view,
+	// stops and returns false if f returns false.
+	eachCell := func(x, y, w, h int, f func(i int) bool) bool {
+		x1, y1, x2, y2 := vx, vy, vx+vw, vy+vh
+		if !d.isVirtual() {
+			x1, y1, x2, y2 = 0, 0, sw, sh
+		}
+		if x1 < 0 {
+			x1 = 0
+		}
+		if y1 < 0 {
+			y1 = 0
+		}
+		if x2 > sw {
+			x2 = sw
+		}
+		if y2 > sh {
+			y2 = sh
+		}
+		if x < x1 {
+			x, w = x1, w-(x1-x)
+		}
+		if y < y1 {
+			y, h = y1, h-(y1-y)
+		}
+		if x+w > x2 {
+			w = x2 - x
+		}
+		if y+h > y2 {
+			h = y2 - y
+		}
+		for j := y; j < y+h; j++ {
+			for i := x; i < x+w; i++ {
+				if !f(j*sw + i) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	isCovered := func(i int) bool { return covered[i] }
+	theme := d.winMgr.GetTheme().ForColors(d.colors)
+	for iwin := len(d.wins) - 1; iwin >= 0; iwin-- {
+		win := d.wins[iwin]
+		if d.isAnimating(win) {
+			d.hidden[iwin] = true
+			continue
+		}
+		x, y, w, h := d.WindowToScreen(win)
+		d.hidden[iwin] = eachCell(x, y, w, h, isCovered)
+		if d.hidden[iwin] && hasShadow(win, &theme) {
+			// The shadow is drawn with the window, which is needed if any of it shows.
+			d.hidden[iwin] = eachCell(x+w, y+1, 1, h, isCovered) && eachCell(x+1, y+h, w-1, 1, isCovered)
+		}
+		if !d.hidden[iwin] && win.backgroundColor(&theme) != tcell.ColorDefault {
+			eachCell(x, y, w, h, func(i int) bool {
+				covered[i] = true
+				return true
+			})
+		}
 	}
+	return eachCell(vx, vy, vw, vh, isCovered)
+}
+
+func (d *Desktop) drawOutlineDrags(screen tcell.Screen) {
 	for _, win := range d.wins {
-		if !init {
-			win.InitWindow()
+		if win.outlining {
+			style := tcell.StyleDefault.Reverse(true)
+			drawOutline(d.windowScreen(screen, win), win.outlineX, win.outlineY, win.outlineW, win.outlineH, style)
 		}
-		win.Draw(screen)
 	}
 }
 
+// windowMouse sends the mouse event to the window in its coordinates.
+// If something in the window captures the mouse, the desktop captures it instead,
+// and sends it the following events in the window's coordinates.
+func (d *Desktop) windowMouse(win *Window, handler func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive),
+	action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	consumed, capture = handler(action, d.windowEvent(win, event), setFocus)
+	d.winCapture, d.winCaptureWin = nil, nil
+	if capture != nil && capture != tview.Primitive(d) && d.drag == nil {
+		d.winCapture, d.winCaptureWin = capture, win
+		capture = d
+	}
+	return consumed, d.dragCapture(capture)
+}
+
 func (d *Desktop) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
 	return d.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if d.drag != nil && event.Key() == tcell.KeyEscape {
+			d.CancelDrag()
+			return
+		}
+		if d.sel != nil && event.Key() == tcell.KeyEscape {
+			d.ClearSelection()
+			return
+		}
+		if d.isVirtual() && (d.panKeys || !d.childFocused()) && d.virtualKey(event) {
+			return
+		}
 		if d.client != nil && d.client.HasFocus() {
 			if handler := d.client.InputHandler(); handler != nil {
 				handler(event, setFocus)
@@ -208,26 +482,59 @@ func (d *Desktop) InputHandler() func(event *tcell.EventKey, setFocus func(p tvi
 func (d *Desktop) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
 	return d.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
 		atX, atY := event.Position()
+		virtual := d.isVirtual()
+		if d.winCapture != nil && d.drag == nil {
+			if d.winCaptureWin.desktop == d {
+				return d.windowMouse(d.winCaptureWin, d.winCapture.MouseHandler(), action, event, setFocus)
+			}
+			d.winCapture, d.winCaptureWin = nil, nil
+		}
+		d.updateHover(atX, atY, action, setFocus)
+		if d.drag != nil {
+			return d.dragMouse(action, event)
+		}
+		if !d.selecting && d.notificationMouse(action, event) {
+			return true, nil
+		}
+		if consumed, capture = d.selectionMouse(action, event); consumed {
+			return
+		}
+		if virtual {
+			if consumed, capture = d.virtualMouseHandler(action, event); consumed {
+				return
+			}
+		}
 		if !d.InRect(atX, atY) {
 			return false, nil
 		}
 
-		// Propagate mouse events; needs to be reverse order, topmost first!
-		for iwin := len(d.wins) - 1; iwin >= 0; iwin-- {
-			win := d.wins[iwin]
-			consumed, capture = win.MouseHandler()(action, event, setFocus)
-			if consumed {
+		if isWheel(action) && d.hover != nil {
+			// The wheel is for the window under the pointer, even if it isn't focused;
+			// if the window doesn't use it, the desktop can.
+			if consumed, capture = d.windowMouse(d.hover, d.hover.MouseHandler(), action, event, setFocus); consumed {
 				return
 			}
+		} else {
+			// Propagate mouse events; needs to be reverse order, topmost first!
+			for iwin := len(d.wins) - 1; iwin >= 0; iwin-- {
+				win := d.wins[iwin]
+				consumed, capture = d.windowMouse(win, win.MouseHandler(), action, event, setFocus)
+				if consumed {
+					return
+				}
+			}
 		}
 		if d.client != nil {
 			if handler := d.client.MouseHandler(); handler != nil {
 				consumed, capture = handler(action, event, setFocus)
 				if consumed {
-					return
+					return consumed, d.dragCapture(capture)
 				}
 			}
 		}
+		if virtual {
+			consumed, capture = d.virtualBackgroundMouse(action, event)
+		}
 		return
 	})
 }
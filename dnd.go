@@ -0,0 +1,231 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+)
+
+// DragText is a drag payload of text.
+type DragText string
+
+// DragFile is a drag payload of a file path.
+type DragFile string
+
+// DropTarget can be implemented by window clients, or primitives in them,
+// to accept drops. The desktop looks for the innermost target under the pointer,
+// through primitives with a GetChildren method, such as Window and ScrollView.
+// Positions are in the target's coordinates, which are desktop space in windows.
+type DropTarget interface {
+	// DragOver is called as a drag moves over the target,
+	// return true if the payload can be dropped at the position.
+	DragOver(drag *Drag, x, y int) bool
+	// DragLeave is called when the drag moves off of the target, or is canceled.
+	DragLeave(drag *Drag)
+	// Drop is called when the drag is released over the target after DragOver accepted it.
+	Drop(drag *Drag, x, y int)
+}
+
+// Drag is a drag and drop in progress, see Desktop.BeginDrag.
+type Drag struct {
+	Payload  interface{}     // DragText, DragFile or a custom type.
+	Source   tview.Primitive // Where the drag started, can be nil.
+	glyph    rune
+	label    string
+	x, y     int
+	target   DropTarget
+	targetX  int // Position in the target's coordinates.
+	targetY  int
+	accepted bool
+	done     func(dropped bool)
+}
+
+// SetGlyph sets the rune drawn next to the pointer.
+func (drag *Drag) SetGlyph(glyph rune) *Drag {
+	drag.glyph = glyph
+	return drag
+}
+
+// SetLabel sets the text drawn next to the glyph, the default is from the payload.
+func (drag *Drag) SetLabel(label string) *Drag {
+	drag.label = label
+	return drag
+}
+
+// SetDoneFunc sets a func called when the drag is dropped or canceled.
+func (drag *Drag) SetDoneFunc(done func(dropped bool)) *Drag {
+	drag.done = done
+	return drag
+}
+
+// GetPosition gets the position of the pointer on the screen.
+func (drag *Drag) GetPosition() (x, y int) {
+	return drag.x, drag.y
+}
+
+// GetTarget gets the drop target under the pointer which accepts the drag, or nil.
+func (drag *Drag) GetTarget() DropTarget {
+	if !drag.accepted {
+		return nil
+	}
+	return drag.target
+}
+
+// dragLabel gets a short description of the payload.
+func dragLabel(payload interface{}) string {
+	var s string
+	switch payload := payload.(type) {
+	case DragText:
+		s = string(payload)
+		if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+			s = s[:i] + "…"
+		}
+	case DragFile:
+		s = filepath.Base(string(payload))
+	case fmt.Stringer:
+		s = payload.String()
+	}
+	if r := []rune(s); len(r) > 20 {
+		s = string(r[:19]) + "…"
+	}
+	return s
+}
+
+// BeginDrag starts dragging the payload from the source, usually from the source's
+// mouse handler while the left button is down. The desktop captures the mouse until
+// the button is released; if the source already captured the mouse, return the desktop
+// as the capture. Escape cancels the drag.
+func (d *Desktop) BeginDrag(payload interface{}, source tview.Primitive) *Drag {
+	d.CancelDrag()
+	d.drag = &Drag{
+		Payload: payload,
+		Source:  source,
+		glyph:   '◆',
+		label:   dragLabel(payload),
+		x:       -1,
+		y:       -1,
+	}
+	return d.drag
+}
+
+// GetDrag gets the drag in progress, or nil.
+func (d *Desktop) GetDrag() *Drag {
+	return d.drag
+}
+
+// CancelDrag cancels the drag in progress, if any.
+func (d *Desktop) CancelDrag() {
+	d.endDrag(false)
+}
+
+func (d *Desktop) endDrag(drop bool) {
+	drag := d.drag
+	if drag == nil {
+		return
+	}
+	d.drag = nil
+	dropped := false
+	if drag.target != nil {
+		if drop && drag.accepted {
+			drag.target.Drop(drag, drag.targetX, drag.targetY)
+			dropped = true
+		} else {
+			drag.target.DragLeave(drag)
+		}
+	}
+	if drag.done != nil {
+		drag.done(dropped)
+	}
+}
+
+// dropTargetAt finds the innermost drop target at the screen position,
+// and the position in the target's coordinates.
+func (d *Desktop) dropTargetAt(x, y int) (target DropTarget, tx, ty int) {
+	if win := d.windowUnder(x, y); win != nil {
+		dx, dy := d.stateOffset(win.state)
+		return findDropTarget(win, x-dx, y-dy), x - dx, y - dy
+	}
+	if d.client != nil && d.InRect(x, y) {
+		return findDropTarget(d.client, x, y), x, y
+	}
+	return nil, x, y
+}
+
+func findDropTarget(p tview.Primitive, x, y int) DropTarget {
+	if parent, ok := p.(interface{ GetChildren() []tview.Primitive }); ok {
+		children := parent.GetChildren()
+		for i := len(children) - 1; i >= 0; i-- {
+			cx, cy, cw, ch := children[i].GetRect()
+			if x >= cx && y >= cy && x < cx+cw && y < cy+ch {
+				if target := findDropTarget(children[i], x, y); target != nil {
+					return target
+				}
+			}
+		}
+	}
+	if target, ok := p.(DropTarget); ok {
+		return target
+	}
+	return nil
+}
+
+// dragCapture gets the mouse capture, which is the desktop if a drag began.
+func (d *Desktop) dragCapture(capture tview.Primitive) tview.Primitive {
+	if d.drag != nil {
+		return d
+	}
+	return capture
+}
+
+// dragMouse tracks the drag with the mouse, dropping when the button is released.
+func (d *Desktop) dragMouse(action tview.MouseAction, event *tcell.EventMouse) (consumed bool, capture tview.Primitive) {
+	drag := d.drag
+	drag.x, drag.y = event.Position()
+	switch action {
+	case tview.MouseLeftUp:
+		d.endDrag(true)
+		return true, nil
+	case tview.MouseRightDown:
+		d.CancelDrag()
+		return true, nil
+	}
+	target, tx, ty := d.dropTargetAt(drag.x, drag.y)
+	if target != drag.target && drag.target != nil {
+		drag.target.DragLeave(drag)
+	}
+	drag.target, drag.targetX, drag.targetY = target, tx, ty
+	drag.accepted = target != nil && target.DragOver(drag, tx, ty)
+	return true, d
+}
+
+// drawDrag draws the glyph and label of the drag next to the pointer.
+func (d *Desktop) drawDrag(screen tcell.Screen, theme *WindowTheme) {
+	drag := d.drag
+	if drag.x < 0 {
+		return
+	}
+	style := tcell.StyleDefault.Foreground(theme.InactiveCaptionTextColor).Background(theme.InactiveCaptionColor)
+	if drag.accepted {
+		style = tcell.StyleDefault.Foreground(theme.ActiveCaptionTextColor).Background(theme.ActiveCaptionColor)
+	}
+	style = style.Reverse(theme.Monochrome && drag.accepted)
+	text := string(drag.glyph)
+	if drag.label != "" {
+		text += " " + drag.label
+	}
+	x := drag.x + 1
+	for _, c := range text {
+		screen.SetContent(x, drag.y, c, nil, style)
+		x += runewidth.RuneWidth(c)
+	}
+}
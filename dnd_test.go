@@ -0,0 +1,183 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+// dragSource begins dragging text when the left button goes down on it.
+type dragSource struct {
+	*tview.Box
+	desktop *tuix.Desktop
+	done    []bool // Results of the done func.
+}
+
+func (s *dragSource) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+	return s.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+		if action == tview.MouseLeftDown && s.InRect(event.Position()) {
+			s.desktop.BeginDrag(tuix.DragText("hello"), s).SetDoneFunc(func(dropped bool) {
+				s.done = append(s.done, dropped)
+			})
+			return true, s // Captured by the source, the desktop takes over.
+		}
+		return false, nil
+	})
+}
+
+// dropTarget records the drag events it gets.
+type dropTarget struct {
+	*tview.Box
+	accept bool
+	events []string
+}
+
+func (dt *dropTarget) DragOver(drag *tuix.Drag, x, y int) bool {
+	dt.events = append(dt.events, fmt.Sprintf("over %d,%d", x, y))
+	return dt.accept
+}
+
+func (dt *dropTarget) DragLeave(drag *tuix.Drag) {
+	dt.events = append(dt.events, "leave")
+}
+
+func (dt *dropTarget) Drop(drag *tuix.Drag, x, y int) {
+	dt.events = append(dt.events, fmt.Sprintf("drop %v %d,%d", drag.Payload, x, y))
+}
+
+// last gets the last event, and clears them.
+func (dt *dropTarget) last() string {
+	if len(dt.events) == 0 {
+		return ""
+	}
+	event := dt.events[len(dt.events)-1]
+	dt.events = nil
+	return event
+}
+
+// dndDesktop has a source window at (2, 2), an accepting target at (30, 2)
+// and a refusing target at (30, 12), all 20x8 with borders.
+func dndDesktop(h *tuixtest.Harness) (*dragSource, *tuix.Window, *dropTarget, *dropTarget) {
+	source := &dragSource{Box: tview.NewBox(), desktop: h.Desktop}
+	accept := &dropTarget{Box: tview.NewBox(), accept: true}
+	refuse := &dropTarget{Box: tview.NewBox()}
+	var acceptWin *tuix.Window
+	for i, client := range []tview.Primitive{source, accept, refuse} {
+		win := tuix.NewWindow()
+		win.SetBorder(true).SetRect([]int{2, 30, 30}[i], []int{2, 2, 12}[i], 20, 8)
+		win.SetClient(client, true)
+		h.Desktop.AddWindow(win)
+		if client == accept {
+			acceptWin = win
+		}
+	}
+	return source, acceptWin, accept, refuse
+}
+
+func TestDragAndDrop(t *testing.T) {
+	h := tuixtest.New(t, 60, 24)
+	defer h.Close()
+	source, _, accept, refuse := dndDesktop(h)
+
+	h.Mouse(5, 5, tcell.Button1, tcell.ModNone)
+	drag := h.Desktop.GetDrag()
+	if drag == nil || drag.Source != source {
+		t.Fatalf("drag is %v", drag)
+	}
+	h.Mouse(35, 5, tcell.Button1, tcell.ModNone)
+	if got := accept.last(); got != "over 35,5" || drag.GetTarget() != accept {
+		t.Errorf("over the accepting window got %q, target %v", got, drag.GetTarget())
+	}
+	h.AssertText(36, 5, "◆ hello")
+	h.Mouse(35, 14, tcell.Button1, tcell.ModNone)
+	if got := accept.last(); got != "leave" {
+		t.Errorf("moving off the accepting window got %q", got)
+	}
+	if got := refuse.last(); got != "over 35,14" || drag.GetTarget() != nil {
+		t.Errorf("over the refusing window got %q, target %v", got, drag.GetTarget())
+	}
+	h.Mouse(36, 6, tcell.Button1, tcell.ModNone)
+	h.Mouse(36, 6, tcell.ButtonNone, tcell.ModNone)
+	if got := accept.last(); got != "drop hello 36,6" {
+		t.Errorf("releasing over the accepting window got %q", got)
+	}
+	if got := refuse.last(); got != "leave" {
+		t.Errorf("refusing window got %q", got)
+	}
+	if h.Desktop.GetDrag() != nil || fmt.Sprint(source.done) != "[true]" {
+		t.Errorf("drag is %v after dropping, done with %v", h.Desktop.GetDrag(), source.done)
+	}
+	if strings.Contains(h.Snapshot(), "◆") {
+		t.Error("drag is still drawn after dropping")
+	}
+
+	// Released over a window which refuses it.
+	h.Drag(5, 5, 35, 14)
+	if got := refuse.last(); got != "leave" || fmt.Sprint(source.done) != "[true false]" {
+		t.Errorf("releasing over the refusing window got %q, done with %v", got, source.done)
+	}
+}
+
+func TestDropPosition(t *testing.T) {
+	h := tuixtest.New(t, 60, 24)
+	defer h.Close()
+	_, acceptWin, accept, _ := dndDesktop(h)
+	acceptWin.SetRect(10, 12, 20, 8)
+	h.Drag(5, 5, 15, 15)
+	if got := accept.last(); got != "drop hello 15,15" {
+		t.Errorf("dropping in the moved window got %q", got)
+	}
+
+	h.Desktop.SetVirtualSize(200, 100)
+	acceptWin.SetRect(40, 20, 20, 8)
+	h.Desktop.ScrollTo(2, 1)
+	h.Draw()
+	// The source is at (0, 1) on the screen, the target at (38, 19).
+	h.Drag(5, 5, 42, 21)
+	if got := accept.last(); got != "drop hello 44,22" {
+		t.Errorf("dropping in the scrolled virtual desktop got %q, expected desktop coordinates", got)
+	}
+}
+
+func TestDragCancel(t *testing.T) {
+	h := tuixtest.New(t, 60, 24)
+	defer h.Close()
+	source, _, accept, _ := dndDesktop(h)
+
+	h.Mouse(5, 5, tcell.Button1, tcell.ModNone)
+	h.Mouse(35, 5, tcell.Button1, tcell.ModNone)
+	h.Key(tcell.KeyEscape, 0, tcell.ModNone)
+	if got := accept.last(); got != "leave" {
+		t.Errorf("escape over the target got %q", got)
+	}
+	h.Mouse(35, 5, tcell.ButtonNone, tcell.ModNone)
+	if got := accept.last(); got != "" {
+		t.Errorf("releasing after escape got %q", got)
+	}
+
+	h.Mouse(5, 5, tcell.Button1, tcell.ModNone)
+	h.Mouse(35, 5, tcell.Button1, tcell.ModNone)
+	h.Mouse(35, 5, tcell.Button1|tcell.Button3, tcell.ModNone)
+	if got := accept.last(); got != "leave" || h.Desktop.GetDrag() != nil {
+		t.Errorf("right click over the target got %q", got)
+	}
+	h.Mouse(35, 5, tcell.ButtonNone, tcell.ModNone)
+
+	h.Mouse(5, 5, tcell.Button1, tcell.ModNone)
+	h.Desktop.CancelDrag()
+	h.Mouse(5, 5, tcell.ButtonNone, tcell.ModNone)
+	if fmt.Sprint(source.done) != "[false false false]" {
+		t.Errorf("canceled drags were done with %v", source.done)
+	}
+}
@@ -3,6 +3,10 @@ module github.com/millerlogic/tuix
 go 1.14
 
 require (
-	github.com/gdamore/tcell/v2 v2.0.1-0.20201017141208-acf90d56d591
+	github.com/BurntSushi/toml v0.3.1
+	github.com/creack/pty v1.1.11
+	github.com/gdamore/tcell/v2 v2.4.0
+	github.com/mattn/go-runewidth v0.0.10
 	github.com/rivo/tview v0.0.0-20210125085121-dbc1f32bb1d0
+	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad
 )
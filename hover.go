@@ -0,0 +1,83 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"github.com/rivo/tview"
+)
+
+// SetFocusFollowsMouse determines if moving the mouse pointer onto a window focuses it,
+// without bringing it to the front. Moving onto the desktop keeps the focus where it was.
+func (d *Desktop) SetFocusFollowsMouse(on bool) *Desktop {
+	d.focusFollows = on
+	return d
+}
+
+// GetHoverWindow gets the window under the mouse pointer, or nil.
+func (d *Desktop) GetHoverWindow() *Window {
+	return d.hover
+}
+
+// windowUnder gets the window the mouse pointer is over, or nil;
+// windows outside the view of a virtual desktop can't be under the pointer.
+func (d *Desktop) windowUnder(x, y int) *Window {
+	if !d.InRect(x, y) {
+		return nil
+	}
+	if d.isVirtual() {
+		vx, vy, vw, vh := d.GetViewRect()
+		if x < vx || y < vy || x >= vx+vw || y >= vy+vh {
+			return nil
+		}
+	}
+	return d.WindowAt(x, y)
+}
+
+// setHover changes the window under the pointer, calling the leave and enter funcs.
+func (d *Desktop) setHover(win *Window) {
+	old := d.hover
+	if old == win {
+		return
+	}
+	d.hover = win
+	if old != nil && old.mouseLeave != nil {
+		old.mouseLeave()
+	}
+	if win != nil && win.mouseEnter != nil {
+		win.mouseEnter()
+	}
+}
+
+// leaveWindow clears the window from every pointer hovering it, it's being removed.
+func (d *Desktop) leaveWindow(win *Window) {
+	for _, p := range d.pointers {
+		if p.hover == win {
+			p.hover = nil
+			if win.mouseLeave != nil {
+				win.mouseLeave()
+			}
+		}
+	}
+}
+
+// updateHover tracks the window under the pointer, and focuses it if focus follows the mouse.
+func (d *Desktop) updateHover(x, y int, action tview.MouseAction, setFocus func(p tview.Primitive)) {
+	win := d.windowUnder(x, y)
+	d.setHover(win)
+	if d.focusFollows && win != nil && action == tview.MouseMove &&
+		win.autoActivate && !win.HasFocus() && d.HasFocus() {
+		setFocus(win)
+	}
+}
+
+func isWheel(action tview.MouseAction) bool {
+	switch action {
+	case tview.MouseScrollUp, tview.MouseScrollDown, tview.MouseScrollLeft, tview.MouseScrollRight:
+		return true
+	}
+	return false
+}
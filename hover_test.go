@@ -0,0 +1,71 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+func TestWheelUnfocusedWindow(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	_, sv := scrollWindow(h)
+	b := textWindow(h, "B", "", 30, 2, 16, 6)
+	h.SetFocus(b)
+	h.Wheel(10, 5, 2)
+	assertScroll(t, sv, 0, 4, "wheel over the unfocused window")
+	h.AssertFocus(b)
+	h.AssertTop(b)
+}
+
+func TestWheelUnconsumedByWindow(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	h.Desktop.SetVirtualSize(200, 60)
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(2, 2, 20, 8)
+	win.SetClient(tview.NewBox(), true) // Doesn't use the wheel.
+	h.Desktop.AddWindow(win)
+	h.Wheel(10, 5, 2)
+	if sx, sy := h.Desktop.GetScroll(); sx != 0 || sy == 0 {
+		t.Errorf("wheel over the window scrolled the desktop to (%d, %d)", sx, sy)
+	}
+}
+
+func TestFocusFollowsMouse(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	a := textWindow(h, "A", "", 2, 2, 16, 6)
+	b := textWindow(h, "B", "", 30, 2, 16, 6)
+	entered := 0
+	a.SetMouseEnterFunc(func() { entered++ })
+	h.SetFocus(b)
+
+	h.MouseMove(5, 4)
+	h.AssertFocus(b)
+	if win := h.Desktop.GetHoverWindow(); win != a {
+		t.Errorf("hover window is %v, expected A", win)
+	}
+	if entered != 1 {
+		t.Errorf("mouse entered A %d times", entered)
+	}
+
+	h.Desktop.SetFocusFollowsMouse(true)
+	h.MouseMove(50, 15)
+	h.MouseMove(5, 4)
+	h.AssertFocus(a)
+	h.AssertTop(b)
+	h.MouseMove(50, 15) // The desktop keeps the focus where it was.
+	h.AssertFocus(a)
+	if win := h.Desktop.GetHoverWindow(); win != nil {
+		t.Errorf("hover window is %v over the desktop", win)
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// DesktopIcon is a labeled icon shown by DesktopIcons.
+type DesktopIcon struct {
+	Glyph    string // Short text drawn above the label, such as "[#]".
+	Label    string
+	Launch   func() // Called when the icon is launched, can be nil.
+	col, row int    // Grid slot.
+	placed   bool   // Has a grid slot, see layout.
+}
+
+// DesktopIcons is a desktop client which lays out labeled icons in a grid.
+// Icons can be selected, dragged to rearrange them, and launched with
+// a double click or the Enter key.
+// The background is not drawn, so the desktop background shows through.
+// Use it with Desktop.SetClient, usually with fullSize set.
+type DesktopIcons struct {
+	*tview.Box
+	icons              []*DesktopIcon
+	selected           *DesktopIcon
+	dragging           *DesktopIcon
+	dragX, dragY       int
+	dragMoved          bool
+	arrange            bool // ArrangeByName at the next layout.
+	iconWidth          int
+	iconHeight         int
+	textColor          tcell.Color
+	selectedTextColor  tcell.Color
+	selectedBackground tcell.Color
+}
+
+// NewDesktopIcons creates a new, empty DesktopIcons.
+func NewDesktopIcons() *DesktopIcons {
+	di := &DesktopIcons{
+		Box:                tview.NewBox(),
+		iconWidth:          10,
+		iconHeight:         3,
+		textColor:          tcell.ColorValid + 255,
+		selectedTextColor:  tcell.ColorValid + 230,
+		selectedBackground: tcell.ColorValid + 26,
+	}
+	di.SetBackgroundColor(tcell.ColorDefault)
+	return di
+}
+
+// SetIconSize sets the size of each grid slot, in cells.
+func (di *DesktopIcons) SetIconSize(width, height int) *DesktopIcons {
+	if width < 1 {
+		width = 1
+	}
+	if height < 2 {
+		height = 2
+	}
+	di.iconWidth, di.iconHeight = width, height
+	return di
+}
+
+// SetTextColor sets the label color of unselected icons.
+func (di *DesktopIcons) SetTextColor(color tcell.Color) *DesktopIcons {
+	di.textColor = color
+	return di
+}
+
+// SetSelectedColors sets the label colors of the selected icon.
+func (di *DesktopIcons) SetSelectedColors(text, background tcell.Color) *DesktopIcons {
+	di.selectedTextColor = text
+	di.selectedBackground = background
+	return di
+}
+
+// AddIcon adds a new icon, it goes in the first free grid slot when next drawn.
+func (di *DesktopIcons) AddIcon(glyph, label string, launch func()) *DesktopIcons {
+	di.icons = append(di.icons, &DesktopIcon{Glyph: glyph, Label: label, Launch: launch})
+	return di
+}
+
+// RemoveIcon removes an icon.
+func (di *DesktopIcons) RemoveIcon(icon *DesktopIcon) *DesktopIcons {
+	for i, xicon := range di.icons {
+		if xicon == icon {
+			copy(di.icons[i:], di.icons[i+1:])
+			di.icons = di.icons[:len(di.icons)-1]
+			if di.selected == icon {
+				di.selected = nil
+			}
+			if di.dragging == icon {
+				di.dragging = nil
+			}
+			break
+		}
+	}
+	return di
+}
+
+// GetIcons gets all the icons, in the order they were added.
+func (di *DesktopIcons) GetIcons() []*DesktopIcon {
+	return di.icons
+}
+
+// GetSelected gets the selected icon, or nil.
+func (di *DesktopIcons) GetSelected() *DesktopIcon {
+	return di.selected
+}
+
+// SetSelected selects an icon, or nil to select nothing.
+func (di *DesktopIcons) SetSelected(icon *DesktopIcon) *DesktopIcons {
+	di.selected = icon
+	return di
+}
+
+// LaunchIcon calls the icon's Launch func, if it has one.
+func (di *DesktopIcons) LaunchIcon(icon *DesktopIcon) {
+	if icon != nil && icon.Launch != nil {
+		icon.Launch()
+	}
+}
+
+// ArrangeByName sorts the icons by label and lays them out from the top left,
+// filling each column first; when next drawn, once the size is known.
+func (di *DesktopIcons) ArrangeByName() *DesktopIcons {
+	di.arrange = true
+	return di
+}
+
+// layout puts the icons in grid slots for the current size, see AddIcon and ArrangeByName.
+func (di *DesktopIcons) layout() {
+	if di.arrange {
+		di.arrange = false
+		sorted := make([]*DesktopIcon, len(di.icons))
+		copy(sorted, di.icons)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return strings.ToLower(sorted[i].Label) < strings.ToLower(sorted[j].Label)
+		})
+		rows := di.gridRows()
+		for i, icon := range sorted {
+			icon.col, icon.row, icon.placed = i/rows, i%rows, true
+		}
+	}
+	for _, icon := range di.icons {
+		if !icon.placed {
+			icon.col, icon.row = di.freeSlot()
+			icon.placed = true
+		}
+	}
+}
+
+func (di *DesktopIcons) gridRows() int {
+	_, _, _, h := di.GetInnerRect()
+	rows := h / di.iconHeight
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+func (di *DesktopIcons) iconAt(col, row int) *DesktopIcon {
+	for _, icon := range di.icons {
+		if icon.placed && icon.col == col && icon.row == row {
+			return icon
+		}
+	}
+	return nil
+}
+
+// freeSlot finds the first slot without an icon, filling each column first.
+func (di *DesktopIcons) freeSlot() (int, int) {
+	rows := di.gridRows()
+	for i := 0; ; i++ {
+		col, row := i/rows, i%rows
+		if di.iconAt(col, row) == nil {
+			return col, row
+		}
+	}
+}
+
+// slotAt gets the grid slot at the screen position.
+func (di *DesktopIcons) slotAt(atX, atY int) (col, row int, ok bool) {
+	x, y, w, h := di.GetInnerRect()
+	if atX < x || atY < y || atX >= x+w || atY >= y+h {
+		return 0, 0, false
+	}
+	return (atX - x) / di.iconWidth, (atY - y) / di.iconHeight, true
+}
+
+func (di *DesktopIcons) Draw(screen tcell.Screen) {
+	di.Box.DrawForSubclass(screen, di)
+	di.layout()
+	x, y, w, h := di.GetInnerRect()
+	for _, icon := range di.icons {
+		if icon == di.dragging && di.dragMoved {
+			continue // Drawn last, under the mouse.
+		}
+		ix, iy := x+icon.col*di.iconWidth, y+icon.row*di.iconHeight
+		if ix+di.iconWidth > x+w || iy+di.iconHeight > y+h {
+			continue
+		}
+		di.drawIcon(screen, icon, ix, iy, icon == di.selected)
+	}
+	if di.dragging != nil && di.dragMoved {
+		if col, row, ok := di.slotAt(di.dragX, di.dragY); ok {
+			di.drawIcon(screen, di.dragging, x+col*di.iconWidth, y+row*di.iconHeight, true)
+		}
+	}
+}
+
+func (di *DesktopIcons) drawIcon(screen tcell.Screen, icon *DesktopIcon, x, y int, selected bool) {
+	width := di.iconWidth - 1 // Leave a gap between icons.
+	tview.Print(screen, tview.Escape(icon.Glyph), x, y, width, tview.AlignCenter, di.textColor)
+	label := tview.Escape(icon.Label)
+	if !selected {
+		tview.Print(screen, label, x, y+1, width, tview.AlignCenter, di.textColor)
+		return
+	}
+	_, printed := tview.Print(screen, label, x, y+1, width, tview.AlignCenter, di.selectedTextColor)
+	style := tcell.StyleDefault.Foreground(di.selectedTextColor).Background(di.selectedBackground)
+	for i := x + (width-printed)/2; i < x+(width-printed)/2+printed; i++ {
+		c, combc, _, _ := screen.GetContent(i, y+1)
+		screen.SetContent(i, y+1, c, combc, style)
+	}
+}
+
+// nextIcon finds the closest icon in the direction of dcol, drow.
+func (di *DesktopIcons) nextIcon(dcol, drow int) *DesktopIcon {
+	if di.selected == nil {
+		if len(di.icons) > 0 {
+			return di.icons[0]
+		}
+		return nil
+	}
+	var best *DesktopIcon
+	bestDist := 0
+	for _, icon := range di.icons {
+		along := (icon.col-di.selected.col)*dcol + (icon.row-di.selected.row)*drow
+		if along <= 0 {
+			continue
+		}
+		across := (icon.col-di.selected.col)*drow + (icon.row-di.selected.row)*dcol
+		if across < 0 {
+			across = -across
+		}
+		dist := along + across*100 // Prefer staying in the same row or column.
+		if best == nil || dist < bestDist {
+			best, bestDist = icon, dist
+		}
+	}
+	return best
+}
+
+func (di *DesktopIcons) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return di.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		di.layout()
+		var next *DesktopIcon
+		switch event.Key() {
+		case tcell.KeyEnter:
+			di.LaunchIcon(di.selected)
+			return
+		case tcell.KeyUp:
+			next = di.nextIcon(0, -1)
+		case tcell.KeyDown:
+			next = di.nextIcon(0, 1)
+		case tcell.KeyLeft:
+			next = di.nextIcon(-1, 0)
+		case tcell.KeyRight:
+			next = di.nextIcon(1, 0)
+		}
+		if next != nil {
+			di.selected = next
+		}
+	})
+}
+
+func (di *DesktopIcons) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return di.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		atX, atY := event.Position()
+		if !di.InRect(atX, atY) && di.dragging == nil {
+			return false, nil
+		}
+		di.layout()
+
+		var icon *DesktopIcon
+		if col, row, ok := di.slotAt(atX, atY); ok {
+			icon = di.iconAt(col, row)
+		}
+
+		switch action {
+		case tview.MouseLeftDown:
+			setFocus(di)
+			di.selected = icon
+			di.dragging = icon
+			di.dragX, di.dragY = atX, atY
+			di.dragMoved = false
+			consumed = true
+		case tview.MouseMove:
+			if di.dragging != nil {
+				if atX != di.dragX || atY != di.dragY {
+					di.dragMoved = true
+				}
+				di.dragX, di.dragY = atX, atY
+				consumed = true
+			}
+		case tview.MouseLeftUp:
+			if di.dragging != nil {
+				if di.dragMoved {
+					if col, row, ok := di.slotAt(atX, atY); ok {
+						if icon != nil && icon != di.dragging {
+							// Swap places with the icon already there.
+							icon.col, icon.row = di.dragging.col, di.dragging.row
+						}
+						di.dragging.col, di.dragging.row = col, row
+					}
+				}
+				di.dragging = nil
+				di.dragMoved = false
+				return true, nil
+			}
+		case tview.MouseLeftDoubleClick:
+			if icon != nil {
+				di.selected = icon
+				di.LaunchIcon(icon)
+				consumed = true
+			}
+		case tview.MouseLeftClick:
+			consumed = true
+		}
+		if di.dragging != nil {
+			capture = di
+		}
+		return
+	})
+}
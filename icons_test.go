@@ -0,0 +1,49 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+)
+
+// launched makes launch funcs which record the label launched.
+func launched(got *string, label string) func() {
+	return func() { *got = label }
+}
+
+func TestIconsPlacedAtLayout(t *testing.T) {
+	h := tuixtest.New(t, 40, 20)
+	defer h.Close()
+	var got string
+	icons := tuix.NewDesktopIcons()
+	for _, label := range []string{"E", "D", "C", "B", "A"} {
+		icons.AddIcon("[#]", label, launched(&got, label))
+	}
+	h.Desktop.SetClient(icons, true)
+	h.Draw()
+	// 20 rows fit 6 icons in the first column, before layout only 3 did.
+	h.DoubleClick(4, 4*3+1)
+	if got != "A" {
+		t.Errorf("launched %q in the fifth slot, expected A", got)
+	}
+
+	icons.ArrangeByName()
+	h.Draw()
+	h.DoubleClick(4, 4*3+1)
+	if got != "E" {
+		t.Errorf("launched %q in the fifth slot after arranging, expected E", got)
+	}
+	icons.AddIcon("[#]", "F", launched(&got, "F"))
+	h.Draw()
+	h.DoubleClick(4, 5*3+1)
+	if got != "F" {
+		t.Errorf("launched %q in the sixth slot, expected F", got)
+	}
+}
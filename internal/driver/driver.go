@@ -0,0 +1,184 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package driver delivers tcell events to primitives the way tview.Application does,
+// for when there's no application, such as shared desktops and tests.
+package driver
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Driver sends events to a root primitive and keeps track of its focus.
+// It is not safe for concurrent use.
+type Driver struct {
+	Root         tview.Primitive
+	focus        tview.Primitive
+	mouseCapture tview.Primitive
+	lastButtons  tcell.ButtonMask
+	lastX, lastY int
+	downX, downY int
+	lastClick    time.Time
+	Now          func() time.Time // For double clicks, nil uses time.Now.
+}
+
+// New creates a driver for the root primitive.
+func New(root tview.Primitive) *Driver {
+	return &Driver{Root: root, lastX: -1, lastY: -1}
+}
+
+// SetFocus focuses the primitive like Application.SetFocus.
+func (drv *Driver) SetFocus(p tview.Primitive) {
+	if drv.focus != nil {
+		drv.focus.Blur()
+	}
+	drv.focus = p
+	if p != nil {
+		p.Focus(drv.SetFocus)
+	}
+}
+
+// GetFocus gets the focused primitive, or nil.
+func (drv *Driver) GetFocus() tview.Primitive {
+	return drv.focus
+}
+
+// Blur blurs the focused primitive but remembers it, see Refocus.
+// This lets drivers which share primitives take turns.
+func (drv *Driver) Blur() {
+	if drv.focus != nil {
+		drv.focus.Blur()
+	}
+}
+
+// Refocus focuses the remembered primitive again after Blur.
+func (drv *Driver) Refocus() {
+	if p := drv.focus; p != nil {
+		drv.focus = nil
+		drv.SetFocus(p)
+	}
+}
+
+// HandleEvent sends a key or mouse event to the root primitive,
+// returns true if it was a key or mouse event.
+func (drv *Driver) HandleEvent(event tcell.Event) bool {
+	switch event := event.(type) {
+	case *tcell.EventKey:
+		if drv.Root != nil && drv.Root.HasFocus() {
+			if handler := drv.Root.InputHandler(); handler != nil {
+				handler(event, drv.SetFocus)
+			}
+		}
+		return true
+	case *tcell.EventMouse:
+		drv.handleMouse(event)
+		return true
+	}
+	return false
+}
+
+func (drv *Driver) now() time.Time {
+	if drv.Now != nil {
+		return drv.Now()
+	}
+	return time.Now()
+}
+
+// handleMouse turns the mouse event into mouse actions like tview.Application.
+func (drv *Driver) handleMouse(event *tcell.EventMouse) {
+	var target tview.Primitive
+	isDown := false
+	fire := func(action tview.MouseAction) {
+		switch action {
+		case tview.MouseLeftDown, tview.MouseMiddleDown, tview.MouseRightDown:
+			isDown = true
+		}
+		var p tview.Primitive
+		if drv.mouseCapture != nil {
+			p = drv.mouseCapture
+			target = drv.mouseCapture
+		} else if target != nil {
+			p = target
+		} else {
+			p = drv.Root
+		}
+		var capture tview.Primitive
+		if p != nil {
+			if handler := p.MouseHandler(); handler != nil {
+				_, capture = handler(action, event, drv.SetFocus)
+			}
+		}
+		drv.mouseCapture = capture
+	}
+
+	x, y := event.Position()
+	buttons := event.Buttons()
+	clickMoved := x != drv.downX || y != drv.downY
+	changes := buttons ^ drv.lastButtons
+	if x != drv.lastX || y != drv.lastY {
+		fire(tview.MouseMove)
+		drv.lastX, drv.lastY = x, y
+	}
+	for _, b := range []struct {
+		button                  tcell.ButtonMask
+		down, up, click, dclick tview.MouseAction
+	}{
+		{tcell.Button1, tview.MouseLeftDown, tview.MouseLeftUp, tview.MouseLeftClick, tview.MouseLeftDoubleClick},
+		{tcell.Button2, tview.MouseMiddleDown, tview.MouseMiddleUp, tview.MouseMiddleClick, tview.MouseMiddleDoubleClick},
+		{tcell.Button3, tview.MouseRightDown, tview.MouseRightUp, tview.MouseRightClick, tview.MouseRightDoubleClick},
+	} {
+		if changes&b.button == 0 {
+			continue
+		}
+		if buttons&b.button != 0 {
+			fire(b.down)
+			continue
+		}
+		fire(b.up)
+		if !clickMoved {
+			if drv.lastClick.Add(tview.DoubleClickInterval).Before(drv.now()) {
+				fire(b.click)
+				drv.lastClick = drv.now()
+			} else {
+				fire(b.dclick)
+				drv.lastClick = time.Time{}
+			}
+		}
+	}
+	for _, w := range []struct {
+		button tcell.ButtonMask
+		action tview.MouseAction
+	}{
+		{tcell.WheelUp, tview.MouseScrollUp},
+		{tcell.WheelDown, tview.MouseScrollDown},
+		{tcell.WheelLeft, tview.MouseScrollLeft},
+		{tcell.WheelRight, tview.MouseScrollRight},
+	} {
+		if buttons&w.button != 0 {
+			fire(w.action)
+		}
+	}
+	if isDown {
+		drv.downX, drv.downY = x, y
+	}
+	drv.lastButtons = buttons
+}
+
+// Draw draws the root primitive filling the screen, and shows it.
+func (drv *Driver) Draw(screen tcell.Screen) {
+	if drv.Root == nil {
+		return
+	}
+	w, h := screen.Size()
+	drv.Root.SetRect(0, 0, w, h)
+	screen.HideCursor()
+	screen.Clear()
+	drv.Root.Draw(screen)
+	screen.Show()
+}
@@ -0,0 +1,259 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// NotifySeverity is how important a notification is, which sets its color.
+type NotifySeverity byte
+
+const (
+	NotifyInfo NotifySeverity = iota
+	NotifySuccess
+	NotifyWarning
+	NotifyError
+)
+
+// NotifyAction is a button of a notification.
+type NotifyAction struct {
+	Label string
+	Func  func()
+}
+
+// NotifyOptions are the options of Desktop.Notify.
+type NotifyOptions struct {
+	Severity NotifySeverity
+	Timeout  time.Duration // Until dismissed; 0 is 5 seconds, negative waits for a click.
+	Click    func()        // Called when the notification is clicked, not on an action.
+	Actions  []NotifyAction
+}
+
+// Notification is a popup shown in the corner of the desktop, see Desktop.Notify.
+type Notification struct {
+	desktop  *Desktop
+	title    string
+	body     string
+	lines    []string
+	opts     NotifyOptions
+	deadline time.Time // Zero if none.
+	timer    *time.Timer
+	x, y     int // Where it was last drawn.
+	w, h     int
+	actionX  []int // Where each action was last drawn.
+}
+
+const notifyWidth = 36
+
+// Notify shows a notification in the bottom right corner of the desktop, above the windows,
+// stacked over the earlier ones. It doesn't take the focus.
+// Like other changes to the desktop, call it from the application's goroutine, such as in QueueUpdateDraw.
+func (d *Desktop) Notify(title, body string, opts *NotifyOptions) *Notification {
+	n := &Notification{desktop: d, title: title, body: body}
+	if opts != nil {
+		n.opts = *opts
+	}
+	if body != "" {
+		n.lines = tview.WordWrap(tview.Escape(body), notifyWidth-2)
+	}
+	timeout := n.opts.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	if timeout > 0 {
+		n.deadline = time.Now().Add(timeout)
+		n.startTimer()
+	}
+	d.notes = append(d.notes, n)
+	return n
+}
+
+// redrawFunc gets a func which draws the desktop, from any goroutine, or nil.
+func (d *Desktop) redrawFunc() func() {
+	if app := d.app; app != nil {
+		return func() { app.QueueUpdateDraw(func() {}) }
+	}
+	return d.redraw
+}
+
+// startTimer redraws the desktop at the deadline, to remove the notification.
+func (n *Notification) startTimer() {
+	if n.timer != nil {
+		n.timer.Stop()
+		n.timer = nil
+	}
+	if redraw := n.desktop.redrawFunc(); redraw != nil {
+		n.timer = time.AfterFunc(time.Until(n.deadline), redraw)
+	}
+}
+
+// restartNotifyTimers starts the notifications' timers over, for a new application.
+func (d *Desktop) restartNotifyTimers() {
+	for _, n := range d.notes {
+		if !n.deadline.IsZero() {
+			n.startTimer()
+		}
+	}
+}
+
+// Dismiss removes the notification.
+func (n *Notification) Dismiss() {
+	d := n.desktop
+	for i, xn := range d.notes {
+		if xn == n {
+			copy(d.notes[i:], d.notes[i+1:])
+			d.notes[len(d.notes)-1] = nil
+			d.notes = d.notes[:len(d.notes)-1]
+			break
+		}
+	}
+	if n.timer != nil {
+		n.timer.Stop()
+	}
+}
+
+// expireNotifications removes the notifications which timed out.
+func (d *Desktop) expireNotifications() {
+	now := time.Now()
+	for i := len(d.notes) - 1; i >= 0; i-- {
+		if n := d.notes[i]; !n.deadline.IsZero() && !now.Before(n.deadline) {
+			n.Dismiss()
+		}
+	}
+}
+
+// notifyStyle gets the style of the caption for the severity.
+func notifyStyle(severity NotifySeverity, theme *WindowTheme) tcell.Style {
+	style := tcell.StyleDefault
+	if theme.Monochrome {
+		return style.Reverse(true).Bold(severity >= NotifyWarning)
+	}
+	fg, bg := theme.ActiveCaptionTextColor, theme.ActiveCaptionColor
+	switch severity {
+	case NotifySuccess:
+		fg, bg = theme.NotifySuccessTextColor, theme.NotifySuccessColor
+	case NotifyWarning:
+		fg, bg = theme.NotifyWarningTextColor, theme.NotifyWarningColor
+	case NotifyError:
+		fg, bg = theme.NotifyErrorTextColor, theme.NotifyErrorColor
+		style = style.Bold(true)
+	}
+	if bg == tcell.ColorDefault {
+		fg, bg = theme.ActiveCaptionTextColor, theme.ActiveCaptionColor
+	}
+	return style.Foreground(fg).Background(bg)
+}
+
+func (d *Desktop) drawNotifications(screen tcell.Screen, theme *WindowTheme) {
+	vx, vy, vw, vh := d.GetViewRect()
+	w := notifyWidth
+	if w > vw {
+		w = vw
+	}
+	bottom := vy + vh
+	for i := len(d.notes) - 1; i >= 0; i-- {
+		n := d.notes[i]
+		h := 2 + len(n.lines)
+		if len(n.opts.Actions) > 0 {
+			h++
+		}
+		n.w, n.h = 0, 0
+		if w < 12 || bottom-h < vy {
+			continue
+		}
+		n.x, n.y, n.w, n.h = vx+vw-w, bottom-h, w, h
+		bottom -= h
+		n.draw(screen, theme)
+	}
+}
+
+func (n *Notification) draw(screen tcell.Screen, theme *WindowTheme) {
+	x, y, w, h := n.x, n.y, n.w, n.h
+	bg := tcell.StyleDefault.Background(theme.ClientBackgroundColor)
+	for j := y; j < y+h; j++ {
+		for i := x; i < x+w; i++ {
+			screen.SetContent(i, j, ' ', nil, bg)
+		}
+	}
+	caption := notifyStyle(n.opts.Severity, theme)
+	borders := theme.Border
+	if borders == (BorderSet{}) {
+		borders = tviewBorders(false)
+	}
+	drawBorder(screen, x, y, w, h, borders, 0, bg.Foreground(theme.ActiveBorderColor).Bold(theme.Monochrome))
+	for i := x; i < x+w; i++ {
+		screen.SetContent(i, y, ' ', nil, caption)
+	}
+	fg, _, _ := caption.Decompose()
+	tview.Print(screen, tview.Escape(n.title), x+1, y, w-4, tview.AlignLeft, fg)
+	screen.SetContent(x+w-2, y, '×', nil, caption)
+	for i, line := range n.lines {
+		tview.Print(screen, line, x+1, y+1+i, w-2, tview.AlignLeft, theme.NotifyTextColor)
+	}
+	if len(n.opts.Actions) > 0 {
+		n.actionX = n.actionX[:0]
+		ax := x + 1
+		for _, action := range n.opts.Actions {
+			label := "[" + action.Label + "]"
+			n.actionX = append(n.actionX, ax)
+			_, width := tview.Print(screen, tview.Escape(label), ax, y+h-2, x+w-1-ax, tview.AlignLeft, theme.NotifyTextColor)
+			for i := ax; i < ax+width; i++ {
+				c, combc, style, _ := screen.GetContent(i, y+h-2)
+				screen.SetContent(i, y+h-2, c, combc, style.Reverse(true))
+			}
+			ax += width + 1
+			if ax >= x+w-1 {
+				break
+			}
+		}
+	}
+}
+
+// notificationMouse handles clicks on the notifications, which are above the windows.
+func (d *Desktop) notificationMouse(action tview.MouseAction, event *tcell.EventMouse) (consumed bool) {
+	atX, atY := event.Position()
+	for i := len(d.notes) - 1; i >= 0; i-- {
+		n := d.notes[i]
+		if atX < n.x || atY < n.y || atX >= n.x+n.w || atY >= n.y+n.h {
+			continue
+		}
+		if action != tview.MouseLeftClick {
+			return true
+		}
+		n.Dismiss()
+		if atY == n.y && atX == n.x+n.w-2 {
+			return true // Close button.
+		}
+		if atY == n.y+n.h-2 && len(n.opts.Actions) > 0 {
+			for j := len(n.actionX) - 1; j >= 0; j-- {
+				if atX >= n.actionX[j] {
+					label := n.opts.Actions[j].Label
+					if atX < n.actionX[j]+len([]rune(label))+2 && n.opts.Actions[j].Func != nil {
+						n.opts.Actions[j].Func()
+						return true
+					}
+					break
+				}
+			}
+		}
+		if n.opts.Click != nil {
+			n.opts.Click()
+		}
+		return true
+	}
+	return false
+}
+
+// String gets the title and text of the notification.
+func (n *Notification) String() string {
+	return strings.TrimSpace(n.title + "\n" + n.body)
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+// notifyCaption draws an error notification, and gets the style of its close button.
+func notifyCaption(t *testing.T, theme tuix.WindowTheme, colors int) tcell.Style {
+	t.Helper()
+	screen := &colorScreen{Screen: simScreen(t, 40, 12), colors: colors}
+	defer screen.Fini()
+	d := tuix.NewDesktop()
+	d.SetRect(0, 0, 40, 12)
+	d.SetWindowManager(tuix.NewWindowManager(theme))
+	d.Notify("Oops", "", &tuix.NotifyOptions{Severity: tuix.NotifyError, Timeout: -1})
+	d.Draw(screen)
+	lines := strings.Split(tuix.ScreenSnapshot(screen, 0, 0, 40, 12).Text(), "\n")
+	for y, line := range lines {
+		if strings.Contains(line, "Oops") {
+			_, _, style, _ := screen.GetContent(38, y)
+			return style
+		}
+	}
+	t.Fatal("notification is not drawn")
+	return tcell.StyleDefault
+}
+
+func TestNotifyThemeColors(t *testing.T) {
+	errorStyle := func(theme *tuix.WindowTheme) tcell.Style {
+		return tcell.StyleDefault.Foreground(theme.NotifyErrorTextColor).Background(theme.NotifyErrorColor).Bold(true)
+	}
+	theme := tuix.ClassicWindowTheme
+	if style := notifyCaption(t, theme, 256); style != errorStyle(&theme) {
+		t.Errorf("256 color error caption is %v", style)
+	}
+	theme8 := theme.ForColors(8)
+	if theme8.NotifyErrorColor == theme.NotifyErrorColor {
+		t.Error("8 color theme has the 256 color error color")
+	}
+	if style := notifyCaption(t, theme, 8); style != errorStyle(&theme8) {
+		t.Errorf("8 color error caption is %v", style)
+	}
+	if style := notifyCaption(t, theme, 2); style != tcell.StyleDefault.Reverse(true).Bold(true) {
+		t.Errorf("monochrome error caption is %v", style)
+	}
+
+	theme.NotifyErrorColor = tcell.ColorDefault
+	want := tcell.StyleDefault.Foreground(theme.ActiveCaptionTextColor).Background(theme.ActiveCaptionColor).Bold(true)
+	if style := notifyCaption(t, theme, 256); style != want {
+		t.Errorf("error caption without a theme color is %v, expected the active caption's", style)
+	}
+}
+
+// notifyBody draws a notification with a body and an action, and gets their styles.
+func notifyBody(t *testing.T, theme tuix.WindowTheme, colors int) (body, action tcell.Style) {
+	t.Helper()
+	screen := &colorScreen{Screen: simScreen(t, 40, 12), colors: colors}
+	defer screen.Fini()
+	d := tuix.NewDesktop()
+	d.SetRect(0, 0, 40, 12)
+	d.SetWindowManager(tuix.NewWindowManager(theme))
+	d.Notify("Note", "hello", &tuix.NotifyOptions{Timeout: -1, Actions: []tuix.NotifyAction{{Label: "OK"}}})
+	d.Draw(screen)
+	lines := strings.Split(tuix.ScreenSnapshot(screen, 0, 0, 40, 12).Text(), "\n")
+	for y, line := range lines {
+		if x := strings.Index(line, "hello"); x >= 0 {
+			x = len([]rune(line[:x]))
+			_, _, body, _ = screen.GetContent(x, y)
+			_, _, action, _ = screen.GetContent(x+1, y+1) // In "[OK]".
+			return body, action
+		}
+	}
+	t.Fatal("notification is not drawn")
+	return
+}
+
+func TestNotifyTextColor(t *testing.T) {
+	theme := tuix.DarkWindowTheme
+	theme.Fallbacks = nil
+	theme.ClientBackgroundColor = tcell.ColorWhite
+	theme.NotifyTextColor = tcell.ColorBlack
+	body, action := notifyBody(t, theme, 256)
+	if want := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite); body != want {
+		t.Errorf("body style is %v, expected the theme's text color %v", body, want)
+	}
+	if want := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite).Reverse(true); action != want {
+		t.Errorf("action style is %v, expected the theme's text color reversed %v", action, want)
+	}
+
+	body, action = notifyBody(t, theme, 2)
+	if want := tcell.StyleDefault.Background(tcell.ColorReset); body != want {
+		t.Errorf("monochrome body style is %v, expected %v", body, want)
+	}
+	if want := tcell.StyleDefault.Background(tcell.ColorReset).Reverse(true); action != want {
+		t.Errorf("monochrome action style is %v, expected %v", action, want)
+	}
+}
+
+// stoppedApp runs an application with the desktop, calls f from it, then stops it.
+func stoppedApp(t *testing.T, d *tuix.Desktop, f func(screen tcell.Screen)) {
+	t.Helper()
+	screen := simScreen(t, 40, 12)
+	app := tview.NewApplication().SetScreen(screen).SetRoot(d, true)
+	d.SetApplication(app)
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+	app.QueueUpdateDraw(func() { f(screen) })
+	app.Stop()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertNoBlockedTimers fails if a timer is left waiting on a stopped application.
+func assertNoBlockedTimers(t *testing.T, wait time.Duration) {
+	t.Helper()
+	time.Sleep(wait)
+	buf := make([]byte, 1<<20)
+	stacks := string(buf[:runtime.Stack(buf, true)])
+	if strings.Contains(stacks, "tview.(*Application).QueueUpdate(") {
+		t.Error("a goroutine is blocked queueing an update")
+	}
+}
+
+func TestNotifyAfterAppStops(t *testing.T) {
+	d := tuix.NewDesktop()
+	stoppedApp(t, d, func(screen tcell.Screen) {
+		d.Notify("Later", "", &tuix.NotifyOptions{Timeout: 20 * time.Millisecond})
+	})
+	d.SetApplication(nil)
+	assertNoBlockedTimers(t, 100*time.Millisecond)
+}
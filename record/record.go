@@ -0,0 +1,147 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package record records the key and mouse input of a desktop, and replays it.
+//
+// Recordings are line-delimited JSON, one Event per line, starting with a header:
+//
+//	{"t":0,"type":"header","version":1}
+//	{"t":0,"type":"resize","width":80,"height":24}
+//	{"t":1520,"type":"mouse","action":"left-down","x":10,"y":3,"buttons":1}
+//	{"t":1734,"type":"key","key":256,"rune":"a"}
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Version is the version of the recording format.
+const Version = 1
+
+// Event types.
+const (
+	TypeHeader = "header"
+	TypeResize = "resize"
+	TypeKey    = "key"
+	TypeMouse  = "mouse"
+)
+
+// Event is one line of a recording.
+type Event struct {
+	Time    int64  `json:"t"` // Milliseconds since the recording started.
+	Type    string `json:"type"`
+	Version int    `json:"version,omitempty"` // Header.
+	Width   int    `json:"width,omitempty"`   // Resize.
+	Height  int    `json:"height,omitempty"`  // Resize.
+	Key     int    `json:"key,omitempty"`     // Key, a tcell.Key.
+	Rune    string `json:"rune,omitempty"`    // Key, for tcell.KeyRune.
+	Action  string `json:"action,omitempty"`  // Mouse, see the action names.
+	X       int    `json:"x,omitempty"`       // Mouse.
+	Y       int    `json:"y,omitempty"`       // Mouse.
+	Buttons int    `json:"buttons,omitempty"` // Mouse, a tcell.ButtonMask.
+	Mod     int    `json:"mod,omitempty"`     // Key and mouse, a tcell.ModMask.
+}
+
+var actionNames = map[tview.MouseAction]string{
+	tview.MouseMove:              "move",
+	tview.MouseLeftDown:          "left-down",
+	tview.MouseLeftUp:            "left-up",
+	tview.MouseLeftClick:         "left-click",
+	tview.MouseLeftDoubleClick:   "left-double-click",
+	tview.MouseMiddleDown:        "middle-down",
+	tview.MouseMiddleUp:          "middle-up",
+	tview.MouseMiddleClick:       "middle-click",
+	tview.MouseMiddleDoubleClick: "middle-double-click",
+	tview.MouseRightDown:         "right-down",
+	tview.MouseRightUp:           "right-up",
+	tview.MouseRightClick:        "right-click",
+	tview.MouseRightDoubleClick:  "right-double-click",
+	tview.MouseScrollUp:          "scroll-up",
+	tview.MouseScrollDown:        "scroll-down",
+	tview.MouseScrollLeft:        "scroll-left",
+	tview.MouseScrollRight:       "scroll-right",
+}
+
+func keyEvent(event *tcell.EventKey) Event {
+	ev := Event{Type: TypeKey, Key: int(event.Key()), Mod: int(event.Modifiers())}
+	if event.Key() == tcell.KeyRune {
+		ev.Rune = string(event.Rune())
+	}
+	return ev
+}
+
+func mouseEvent(action tview.MouseAction, event *tcell.EventMouse) Event {
+	x, y := event.Position()
+	return Event{Type: TypeMouse, Action: actionNames[action], X: x, Y: y,
+		Buttons: int(event.Buttons()), Mod: int(event.Modifiers())}
+}
+
+// EventKey gets the key event of a key Event.
+func (ev Event) EventKey() *tcell.EventKey {
+	var r rune
+	for _, r = range ev.Rune {
+		break
+	}
+	return tcell.NewEventKey(tcell.Key(ev.Key), r, tcell.ModMask(ev.Mod))
+}
+
+// EventMouse gets the mouse action and event of a mouse Event,
+// ok is false if the action is not known.
+func (ev Event) EventMouse() (action tview.MouseAction, event *tcell.EventMouse, ok bool) {
+	for action, name := range actionNames {
+		if name == ev.Action {
+			event = tcell.NewEventMouse(ev.X, ev.Y, tcell.ButtonMask(ev.Buttons), tcell.ModMask(ev.Mod))
+			return action, event, true
+		}
+	}
+	return 0, nil, false
+}
+
+// ReadEvents reads a recording.
+func ReadEvents(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("record: line %d: %v", line, err)
+		}
+		if ev.Type == TypeHeader && ev.Version > Version {
+			return nil, fmt.Errorf("record: line %d: unsupported version %d", line, ev.Version)
+		}
+		events = append(events, ev)
+	}
+	return events, scanner.Err()
+}
+
+// mouseTarget sends mouse actions to a root primitive, or the primitive which captured the mouse,
+// like tview.Application.
+type mouseTarget struct {
+	root    tview.Primitive
+	capture tview.Primitive
+}
+
+func (mt *mouseTarget) mouse(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	p := mt.root
+	if mt.capture != nil {
+		p = mt.capture
+	}
+	if handler := p.MouseHandler(); handler != nil {
+		consumed, capture = handler(action, event, setFocus)
+	}
+	mt.capture = capture
+	return
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package record
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+// newDesktop creates a drawn desktop with a window, returns the window.
+func newDesktop(t *testing.T) (*tuix.Desktop, *tuix.Window) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(40, 12)
+	d := tuix.NewDesktop()
+	d.SetRect(0, 0, 40, 12)
+	win := tuix.NewWindow()
+	win.SetTitle("A").SetBorder(true).SetRect(2, 2, 20, 6)
+	d.AddWindow(win)
+	d.Draw(screen)
+	screen.Fini()
+	return d, win
+}
+
+func noFocus(p tview.Primitive) {}
+
+func TestRecordReplay(t *testing.T) {
+	d, win := newDesktop(t)
+	buf := &bytes.Buffer{}
+	rec := NewRecorder(d, buf)
+	clock := rec.start
+	rec.now = func() time.Time { return clock }
+	rec.SetRect(0, 0, 40, 12)
+	mouse := rec.MouseHandler()
+	if _, capture := mouse(tview.MouseLeftDown, tcell.NewEventMouse(5, 2, tcell.Button1, 0), noFocus); capture != rec {
+		t.Errorf("recorder did not capture the mouse to move the window, captured %v", capture)
+	}
+	clock = clock.Add(100 * time.Millisecond)
+	mouse(tview.MouseMove, tcell.NewEventMouse(9, 4, tcell.Button1, 0), noFocus)
+	clock = clock.Add(50 * time.Millisecond)
+	mouse(tview.MouseLeftUp, tcell.NewEventMouse(9, 4, tcell.ButtonNone, 0), noFocus)
+	rec.SetPaused(true)
+	rec.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'y', 0), noFocus)
+	rec.SetPaused(false)
+	rec.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), noFocus)
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if x, y, _, _ := win.GetRect(); x != 6 || y != 4 {
+		t.Errorf("recorded window moved to (%d, %d)", x, y)
+	}
+
+	events, err := ReadEvents(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{
+		{Type: TypeHeader, Version: Version},
+		{Type: TypeResize, Width: 40, Height: 12},
+		{Type: TypeMouse, Action: "left-down", X: 5, Y: 2, Buttons: int(tcell.Button1)},
+		{Time: 100, Type: TypeMouse, Action: "move", X: 9, Y: 4, Buttons: int(tcell.Button1)},
+		{Time: 150, Type: TypeMouse, Action: "left-up", X: 9, Y: 4},
+		{Time: 150, Type: TypeKey, Key: int(tcell.KeyRune), Rune: "x", Mod: int(tcell.ModAlt)},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("recorded %+v\nexpected %+v", events, want)
+	}
+	if key := events[5].EventKey(); key.Key() != tcell.KeyRune || key.Rune() != 'x' || key.Modifiers() != tcell.ModAlt {
+		t.Errorf("key event is %v", key.Name())
+	}
+
+	d2, win2 := newDesktop(t)
+	rep := NewReplayer(d2, events)
+	for rep.Step(noFocus) {
+	}
+	if !rep.Done() {
+		t.Error("replayer is not done")
+	}
+	if x, y, _, _ := win2.GetRect(); x != 6 || y != 4 {
+		t.Errorf("replayed window moved to (%d, %d)", x, y)
+	}
+}
+
+func TestReadEventsErrors(t *testing.T) {
+	if _, err := ReadEvents(strings.NewReader(`{"t":0,"type":"header","version":99}`)); err == nil {
+		t.Error("read a newer version")
+	}
+	if _, err := ReadEvents(strings.NewReader("{\"t\":0,\"type\":\"header\",\"version\":1}\n\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("bad line returned %v", err)
+	}
+	if _, _, ok := (Event{Type: TypeMouse, Action: "wiggle"}).EventMouse(); ok {
+		t.Error("unknown mouse action was replayed")
+	}
+}
+
+func TestPlay(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(40, 12)
+	d, win := newDesktop(t)
+	app := tview.NewApplication().SetScreen(screen).SetRoot(d, true)
+	d.SetApplication(app)
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+	defer func() {
+		app.Stop()
+		<-done
+	}()
+	rep := NewReplayer(d, []Event{
+		{Type: TypeHeader, Version: Version},
+		{Time: 10, Type: TypeMouse, Action: "left-down", X: 5, Y: 2, Buttons: int(tcell.Button1)},
+		{Time: 20, Type: TypeMouse, Action: "move", X: 7, Y: 3, Buttons: int(tcell.Button1)},
+		{Time: 30, Type: TypeMouse, Action: "left-up", X: 7, Y: 3},
+	}).SetSpeed(10)
+	rep.Play(app)
+	moved := make(chan [2]int)
+	app.QueueUpdate(func() {
+		x, y, _, _ := win.GetRect()
+		go func() { moved <- [2]int{x, y} }()
+	})
+	if at := <-moved; at != [2]int{4, 3} {
+		t.Errorf("played window moved to %v", at)
+	}
+	rep.Stop()
+	rep.Stop() // Again is fine.
+}
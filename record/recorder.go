@@ -0,0 +1,105 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package record
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+// Recorder is a desktop which records its input, use it as the root instead of the desktop:
+//
+//	rec := record.NewRecorder(desktop, f)
+//	app.SetRoot(rec, true)
+type Recorder struct {
+	*tuix.Desktop
+	mu     sync.Mutex
+	enc    *json.Encoder
+	start  time.Time
+	target mouseTarget
+	width  int
+	height int
+	paused bool
+	err    error
+	now    func() time.Time
+}
+
+// NewRecorder creates a recorder of the desktop's input, writing the recording to w.
+func NewRecorder(d *tuix.Desktop, w io.Writer) *Recorder {
+	rec := &Recorder{
+		Desktop: d,
+		enc:     json.NewEncoder(w),
+		now:     time.Now,
+	}
+	rec.start = rec.now()
+	rec.target.root = d
+	rec.write(Event{Type: TypeHeader, Version: Version})
+	return rec
+}
+
+// SetPaused stops or resumes recording.
+func (rec *Recorder) SetPaused(paused bool) *Recorder {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.paused = paused
+	return rec
+}
+
+// Err gets the first error writing the recording, recording stops after an error.
+func (rec *Recorder) Err() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.err
+}
+
+func (rec *Recorder) write(ev Event) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.paused || rec.err != nil {
+		return
+	}
+	ev.Time = int64(rec.now().Sub(rec.start) / time.Millisecond)
+	rec.err = rec.enc.Encode(ev)
+}
+
+// SetRect records the size when it changes.
+func (rec *Recorder) SetRect(x, y, width, height int) {
+	if width != rec.width || height != rec.height {
+		rec.width, rec.height = width, height
+		rec.write(Event{Type: TypeResize, Width: width, Height: height})
+	}
+	rec.Desktop.SetRect(x, y, width, height)
+}
+
+// InputHandler records the key and passes it to the desktop.
+func (rec *Recorder) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		rec.write(keyEvent(event))
+		if handler := rec.Desktop.InputHandler(); handler != nil {
+			handler(event, setFocus)
+		}
+	}
+}
+
+// MouseHandler records the mouse action and passes it to the desktop,
+// or what it captured the mouse for.
+func (rec *Recorder) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		rec.write(mouseEvent(action, event))
+		consumed, capture = rec.target.mouse(action, event, setFocus)
+		if capture != nil {
+			capture = rec // Keep recording while captured.
+		}
+		return
+	}
+}
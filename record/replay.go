@@ -0,0 +1,137 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package record
+
+import (
+	"sync"
+	"time"
+
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+// Replayer feeds a recording back into a desktop.
+// Use Step to replay synchronously, such as in tests, or Play to replay in an application.
+type Replayer struct {
+	desktop *tuix.Desktop
+	events  []Event
+	pos     int
+	target  mouseTarget
+	speed   float64
+	stopMu  sync.Mutex
+	stop    chan struct{}
+}
+
+// NewReplayer creates a replayer of the events into the desktop, see ReadEvents.
+func NewReplayer(d *tuix.Desktop, events []Event) *Replayer {
+	rep := &Replayer{
+		desktop: d,
+		events:  events,
+		speed:   1,
+		stop:    make(chan struct{}),
+	}
+	rep.target.root = d
+	return rep
+}
+
+// SetSpeed sets how fast Play replays, 2 is twice as fast; 0 replays without waiting.
+func (rep *Replayer) SetSpeed(speed float64) *Replayer {
+	rep.speed = speed
+	return rep
+}
+
+// Done reports if every event was replayed.
+func (rep *Replayer) Done() bool {
+	return rep.pos >= len(rep.events)
+}
+
+// Next gets the next event to replay, ok is false when done.
+func (rep *Replayer) Next() (ev Event, ok bool) {
+	if rep.Done() {
+		return Event{}, false
+	}
+	return rep.events[rep.pos], true
+}
+
+// Step replays the next event and returns true, or returns false when done.
+// The setFocus func is like the one passed to handlers, such as Application.SetFocus.
+// Resize events set the desktop's rect, which an application resets when it draws.
+func (rep *Replayer) Step(setFocus func(p tview.Primitive)) bool {
+	ev, ok := rep.Next()
+	if !ok {
+		return false
+	}
+	rep.pos++
+	d := rep.desktop
+	switch ev.Type {
+	case TypeResize:
+		d.SetRect(0, 0, ev.Width, ev.Height)
+	case TypeKey:
+		if d.HasFocus() {
+			if handler := d.InputHandler(); handler != nil {
+				handler(ev.EventKey(), setFocus)
+			}
+		}
+	case TypeMouse:
+		if action, event, ok := ev.EventMouse(); ok {
+			rep.target.mouse(action, event, setFocus)
+		}
+	}
+	return true
+}
+
+// Play replays the rest of the events into the application's desktop at the recorded times,
+// it returns when done or after Stop. Don't call it from the application's goroutine.
+func (rep *Replayer) Play(app *tview.Application) {
+	setFocus := func(p tview.Primitive) { app.SetFocus(p) }
+	start := time.Now()
+	var offset int64
+	if ev, ok := rep.Next(); ok {
+		offset = ev.Time
+	}
+	for {
+		ev, ok := rep.Next()
+		if !ok {
+			return
+		}
+		if rep.speed > 0 {
+			at := start.Add(time.Duration(float64(ev.Time-offset)/rep.speed) * time.Millisecond)
+			select {
+			case <-time.After(time.Until(at)):
+			case <-rep.stop:
+				return
+			}
+		} else {
+			select {
+			case <-rep.stop:
+				return
+			default:
+			}
+		}
+		done := make(chan struct{})
+		app.QueueUpdateDraw(func() {
+			defer close(done)
+			rep.Step(setFocus)
+		})
+		select {
+		case <-done:
+		case <-rep.stop:
+			return
+		}
+	}
+}
+
+// Stop stops Play.
+func (rep *Replayer) Stop() {
+	rep.stopMu.Lock()
+	defer rep.stopMu.Unlock()
+	select {
+	case <-rep.stop:
+	default:
+		close(rep.stop)
+	}
+}
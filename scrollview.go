@@ -0,0 +1,390 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// BorderOwner can be implemented by a window client to draw in the window's border
+// and handle the mouse there, such as ScrollView's scrollbars.
+// The window manager calls DrawBorder after drawing the border,
+// and BorderMouseHandler before its own mouse handling, such as resizing from the border.
+type BorderOwner interface {
+	DrawBorder(win *Window, screen tcell.Screen)
+	BorderMouseHandler(win *Window, action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive)
+}
+
+// ScrollView shows part of a child primitive which has a larger virtual size, with scrollbars.
+// As the full size client of a window with a border, the scrollbars are in the window's border,
+// otherwise they take space from the scroll view.
+// Scroll with the mouse wheel, the scrollbars, or ctrl with the arrow keys and page up and down.
+type ScrollView struct {
+	*tview.Box
+	child         tview.Primitive
+	virtW, virtH  int
+	scrollX       int
+	scrollY       int
+	inBorder      bool // Scrollbars are in the window's border.
+	borderDrawn   bool // DrawBorder was called before Draw.
+	theme         *WindowTheme
+	scrollDrag    byte // 1=horiz, 2=vert
+	scrollDragOff int
+}
+
+var _ BorderOwner = &ScrollView{}
+
+// NewScrollView creates a scroll view of the child, see SetVirtualSize.
+func NewScrollView(child tview.Primitive) *ScrollView {
+	return &ScrollView{
+		Box:   tview.NewBox(),
+		child: child,
+	}
+}
+
+// GetChild gets the child primitive.
+func (sv *ScrollView) GetChild() tview.Primitive {
+	return sv.child
+}
+
+// SetChild sets the child primitive.
+func (sv *ScrollView) SetChild(child tview.Primitive) *ScrollView {
+	sv.child = child
+	return sv
+}
+
+// SetVirtualSize sets the size of the child, a size of 0 uses the size of the view,
+// such as to only scroll vertically.
+func (sv *ScrollView) SetVirtualSize(width, height int) *ScrollView {
+	sv.virtW, sv.virtH = width, height
+	sv.ScrollTo(sv.scrollX, sv.scrollY)
+	return sv
+}
+
+// GetVirtualSize gets the size of the child.
+func (sv *ScrollView) GetVirtualSize() (width, height int) {
+	_, _, w, h := sv.GetViewRect()
+	if sv.virtW > w {
+		w = sv.virtW
+	}
+	if sv.virtH > h {
+		h = sv.virtH
+	}
+	return w, h
+}
+
+// scrollbars gets which scrollbars are shown.
+func (sv *ScrollView) scrollbars() (horiz, vert bool) {
+	_, _, w, h := sv.GetInnerRect()
+	if sv.inBorder {
+		return sv.virtW > w, sv.virtH > h
+	}
+	vert = sv.virtH > h
+	if vert {
+		w--
+	}
+	horiz = sv.virtW > w
+	if horiz && !vert && sv.virtH > h-1 {
+		vert = true
+	}
+	return
+}
+
+// GetViewRect gets the part of the inner rect which shows the child.
+func (sv *ScrollView) GetViewRect() (x, y, width, height int) {
+	x, y, width, height = sv.GetInnerRect()
+	if sv.inBorder {
+		return
+	}
+	horiz, vert := sv.scrollbars()
+	if vert {
+		width--
+	}
+	if horiz {
+		height--
+	}
+	return
+}
+
+// GetScroll gets the position of the child at the top left of the view.
+func (sv *ScrollView) GetScroll() (x, y int) {
+	return sv.scrollX, sv.scrollY
+}
+
+// ScrollTo scrolls the view to the position of the child, within its virtual size.
+func (sv *ScrollView) ScrollTo(x, y int) *ScrollView {
+	_, _, vw, vh := sv.GetViewRect()
+	virtW, virtH := sv.GetVirtualSize()
+	if x > virtW-vw {
+		x = virtW - vw
+	}
+	if y > virtH-vh {
+		y = virtH - vh
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	sv.scrollX, sv.scrollY = x, y
+	return sv
+}
+
+// ScrollBy scrolls the view by the number of cells.
+func (sv *ScrollView) ScrollBy(dx, dy int) *ScrollView {
+	return sv.ScrollTo(sv.scrollX+dx, sv.scrollY+dy)
+}
+
+func (sv *ScrollView) SetRect(x, y, width, height int) {
+	sv.Box.SetRect(x, y, width, height)
+	sv.ScrollTo(sv.scrollX, sv.scrollY) // The range changes with the size.
+}
+
+// layout sets the child's rect for the scroll position.
+func (sv *ScrollView) layout() {
+	if sv.child == nil {
+		return
+	}
+	vx, vy, _, _ := sv.GetViewRect()
+	virtW, virtH := sv.GetVirtualSize()
+	sv.child.SetRect(vx-sv.scrollX, vy-sv.scrollY, virtW, virtH)
+}
+
+// DrawBorder draws the scrollbars in the window's border, if the scroll view fills the window.
+func (sv *ScrollView) DrawBorder(win *Window, screen tcell.Screen) {
+	if !win.border || !win.clientFullSize {
+		return
+	}
+	sv.borderDrawn = true
+	sv.inBorder = true
+	theme := DefaultWindowTheme
+	if d := win.desktop; d != nil {
+		theme = d.winMgr.GetTheme().ForColors(d.colors) // Like the border.
+	}
+	sv.theme = &theme
+	sv.ScrollTo(sv.scrollX, sv.scrollY)
+	sv.drawScrollbars(screen, win.backgroundColor(&theme))
+}
+
+func (sv *ScrollView) drawScrollbars(screen tcell.Screen, bg tcell.Color) {
+	horiz, vert := sv.scrollbars()
+	vx, vy, vw, vh := sv.GetViewRect()
+	virtW, virtH := sv.GetVirtualSize()
+	if horiz {
+		drawScrollbar(screen, vx, vy+vh, vw, false, vw, virtW, sv.scrollX, sv.theme, bg)
+	}
+	if vert {
+		drawScrollbar(screen, vx+vw, vy, vh, true, vh, virtH, sv.scrollY, sv.theme, bg)
+	}
+	if horiz && vert && !sv.inBorder {
+		screen.SetContent(vx+vw, vy+vh, ' ', nil, tcell.StyleDefault.Background(bg))
+	}
+}
+
+func (sv *ScrollView) Draw(screen tcell.Screen) {
+	if !sv.borderDrawn && sv.inBorder {
+		sv.inBorder = false // No longer drawn in a border.
+		sv.ScrollTo(sv.scrollX, sv.scrollY)
+	}
+	sv.borderDrawn = false
+	sv.Box.DrawForSubclass(screen, sv)
+	sv.layout()
+	if sv.child != nil {
+		vx, vy, vw, vh := sv.GetViewRect()
+		sv.child.Draw(ClipScreen(screen, vx, vy, vw, vh))
+	}
+	if !sv.inBorder {
+		if sv.theme == nil {
+			sv.theme = &DefaultWindowTheme
+		}
+		sv.drawScrollbars(screen, sv.GetBackgroundColor())
+	}
+}
+
+func (sv *ScrollView) GetChildren() []tview.Primitive {
+	if sv.child != nil {
+		return []tview.Primitive{sv.child}
+	}
+	return nil
+}
+
+func (sv *ScrollView) Focus(delegate func(p tview.Primitive)) {
+	if sv.child != nil {
+		delegate(sv.child)
+		return
+	}
+	sv.Box.Focus(delegate)
+}
+
+func (sv *ScrollView) HasFocus() bool {
+	if sv.child != nil && sv.child.HasFocus() {
+		return true
+	}
+	return sv.Box.HasFocus()
+}
+
+func (sv *ScrollView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return sv.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if event.Modifiers()&tcell.ModCtrl != 0 {
+			_, _, vw, vh := sv.GetViewRect()
+			switch event.Key() {
+			case tcell.KeyLeft:
+				sv.ScrollBy(-1, 0)
+				return
+			case tcell.KeyRight:
+				sv.ScrollBy(1, 0)
+				return
+			case tcell.KeyUp:
+				sv.ScrollBy(0, -1)
+				return
+			case tcell.KeyDown:
+				sv.ScrollBy(0, 1)
+				return
+			case tcell.KeyPgUp:
+				sv.ScrollBy(0, -vh)
+				return
+			case tcell.KeyPgDn:
+				sv.ScrollBy(0, vh)
+				return
+			case tcell.KeyHome:
+				sv.ScrollBy(-vw, 0)
+				return
+			case tcell.KeyEnd:
+				sv.ScrollBy(vw, 0)
+				return
+			}
+		}
+		if sv.child != nil && sv.child.HasFocus() {
+			if handler := sv.child.InputHandler(); handler != nil {
+				handler(event, setFocus)
+			}
+		}
+	})
+}
+
+// BorderMouseHandler handles the scrollbars in the window's border.
+func (sv *ScrollView) BorderMouseHandler(win *Window, action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	if !sv.inBorder {
+		return false, nil
+	}
+	return sv.scrollbarMouse(action, event)
+}
+
+// scrollbarMouse handles dragging the thumbs and clicking the tracks.
+func (sv *ScrollView) scrollbarMouse(action tview.MouseAction, event *tcell.EventMouse) (consumed bool, capture tview.Primitive) {
+	atX, atY := event.Position()
+	vx, vy, vw, vh := sv.GetViewRect()
+	virtW, virtH := sv.GetVirtualSize()
+
+	if sv.scrollDrag != 0 {
+		switch action {
+		case tview.MouseMove:
+			if sv.scrollDrag == 1 {
+				_, n := scrollThumb(vw, vw, virtW, sv.scrollX)
+				if vw > n {
+					sv.ScrollTo((atX-vx-sv.scrollDragOff)*(virtW-vw)/(vw-n), sv.scrollY)
+				}
+			} else {
+				_, n := scrollThumb(vh, vh, virtH, sv.scrollY)
+				if vh > n {
+					sv.ScrollTo(sv.scrollX, (atY-vy-sv.scrollDragOff)*(virtH-vh)/(vh-n))
+				}
+			}
+		case tview.MouseLeftUp:
+			sv.scrollDrag = 0
+			return true, nil
+		}
+		return true, sv
+	}
+
+	horiz, vert := sv.scrollbars()
+	onHoriz := horiz && atY == vy+vh && atX >= vx && atX < vx+vw
+	onVert := vert && atX == vx+vw && atY >= vy && atY < vy+vh
+	if !onHoriz && !onVert {
+		return false, nil
+	}
+	switch action {
+	case tview.MouseLeftDown:
+		if onHoriz {
+			pos, n := scrollThumb(vw, vw, virtW, sv.scrollX)
+			if i := atX - vx; i < pos {
+				sv.ScrollBy(-vw, 0)
+			} else if i >= pos+n {
+				sv.ScrollBy(vw, 0)
+			} else {
+				sv.scrollDrag, sv.scrollDragOff = 1, i-pos
+				return true, sv
+			}
+		} else {
+			pos, n := scrollThumb(vh, vh, virtH, sv.scrollY)
+			if j := atY - vy; j < pos {
+				sv.ScrollBy(0, -vh)
+			} else if j >= pos+n {
+				sv.ScrollBy(0, vh)
+			} else {
+				sv.scrollDrag, sv.scrollDragOff = 2, j-pos
+				return true, sv
+			}
+		}
+	case tview.MouseScrollUp, tview.MouseScrollDown, tview.MouseScrollLeft, tview.MouseScrollRight:
+		sv.wheel(action, onHoriz)
+	}
+	return true, nil
+}
+
+// wheel scrolls for the mouse wheel, up and down scroll horizontally if horiz.
+func (sv *ScrollView) wheel(action tview.MouseAction, horiz bool) {
+	_, _, vw, vh := sv.GetViewRect()
+	dx, dy := 0, 0
+	switch action {
+	case tview.MouseScrollUp:
+		dy = -(vh/8 + 1)
+	case tview.MouseScrollDown:
+		dy = vh/8 + 1
+	case tview.MouseScrollLeft:
+		dx = -(vw/8 + 1)
+	case tview.MouseScrollRight:
+		dx = vw/8 + 1
+	}
+	if horiz && dy != 0 {
+		dx, dy = vw/8+1, 0
+		if action == tview.MouseScrollUp {
+			dx = -dx
+		}
+	}
+	sv.ScrollBy(dx, dy)
+}
+
+func (sv *ScrollView) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return sv.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		if sv.scrollDrag != 0 || !sv.inBorder {
+			if consumed, capture = sv.scrollbarMouse(action, event); consumed {
+				return
+			}
+		}
+		atX, atY := event.Position()
+		vx, vy, vw, vh := sv.GetViewRect()
+		if atX < vx || atY < vy || atX >= vx+vw || atY >= vy+vh {
+			return false, nil
+		}
+		switch action {
+		case tview.MouseScrollUp, tview.MouseScrollDown, tview.MouseScrollLeft, tview.MouseScrollRight:
+			horiz, vert := sv.scrollbars()
+			sv.wheel(action, horiz && (!vert || event.Modifiers()&tcell.ModShift != 0))
+			return true, nil
+		}
+		sv.layout()
+		if sv.child != nil {
+			if handler := sv.child.MouseHandler(); handler != nil {
+				return handler(action, event, setFocus)
+			}
+		}
+		return false, nil
+	})
+}
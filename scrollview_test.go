@@ -0,0 +1,169 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+func TestScrollViewBorderColors(t *testing.T) {
+	screen := &colorScreen{Screen: simScreen(t, 40, 12), colors: 8}
+	defer screen.Fini()
+	d := tuix.NewDesktop()
+	d.SetRect(0, 0, 40, 12)
+	d.SetWindowManager(tuix.NewWindowManager(tuix.DarkWindowTheme))
+	sv := tuix.NewScrollView(tview.NewTextView()).SetVirtualSize(0, 50)
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(2, 2, 20, 8)
+	win.SetClient(sv, true)
+	d.AddWindow(win)
+	d.Draw(screen)
+
+	theme := tuix.DarkWindowTheme.ForColors(8)
+	_, _, style, _ := screen.GetContent(21, 5) // Track, in the right border.
+	if want := tcell.StyleDefault.Foreground(theme.InactiveBorderColor).Background(theme.ClientBackgroundColor); style != want {
+		t.Errorf("scrollbar style is %v, expected %v from the 8 color theme", style, want)
+	}
+
+	win.SetBackgroundColor(tcell.ColorNavy)
+	d.Draw(screen)
+	if _, _, style, _ := screen.GetContent(21, 5); style != style.Background(tcell.ColorNavy) {
+		t.Errorf("scrollbar style is %v, expected the window's background", style)
+	}
+}
+
+// scrollWindow adds a window at (2, 2) with a 20x8 scroll view over 60x50, its scrollbars in the border:
+// vertical at x=23 from y=3 to 10, horizontal at y=11 from x=3 to 22.
+func scrollWindow(h *tuixtest.Harness) (*tuix.Window, *tuix.ScrollView) {
+	sv := tuix.NewScrollView(tview.NewBox()).SetVirtualSize(60, 50)
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(2, 2, 22, 10)
+	win.SetClient(sv, true)
+	h.Desktop.AddWindow(win)
+	h.SetFocus(win)
+	return win, sv
+}
+
+func assertScroll(t *testing.T, sv *tuix.ScrollView, x, y int, what string) {
+	t.Helper()
+	if sx, sy := sv.GetScroll(); sx != x || sy != y {
+		t.Errorf("%s scrolled to (%d, %d), expected (%d, %d)", what, sx, sy, x, y)
+	}
+}
+
+// wheel turns the mouse wheel once at the position.
+func wheel(h *tuixtest.Harness, x, y int, button tcell.ButtonMask, mod tcell.ModMask) {
+	h.Mouse(x, y, button, mod)
+	h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+func TestScrollViewWheel(t *testing.T) {
+	h := tuixtest.New(t, 40, 20)
+	defer h.Close()
+	_, sv := scrollWindow(h)
+	wheel(h, 10, 5, tcell.WheelDown, tcell.ModNone)
+	assertScroll(t, sv, 0, 2, "wheel down")
+	wheel(h, 10, 5, tcell.WheelUp, tcell.ModNone)
+	wheel(h, 10, 5, tcell.WheelUp, tcell.ModNone)
+	assertScroll(t, sv, 0, 0, "wheel up past the top")
+	wheel(h, 10, 5, tcell.WheelDown, tcell.ModShift)
+	assertScroll(t, sv, 3, 0, "shift+wheel down")
+	wheel(h, 10, 11, tcell.WheelDown, tcell.ModNone)
+	assertScroll(t, sv, 6, 0, "wheel down on the horizontal scrollbar")
+	wheel(h, 23, 5, tcell.WheelDown, tcell.ModNone)
+	assertScroll(t, sv, 6, 2, "wheel down on the vertical scrollbar")
+}
+
+func TestScrollViewScrollbarMouse(t *testing.T) {
+	h := tuixtest.New(t, 40, 20)
+	defer h.Close()
+	win, sv := scrollWindow(h)
+	h.Click(23, 10)
+	assertScroll(t, sv, 0, 8, "clicking the track below the thumb")
+	h.Click(23, 3)
+	assertScroll(t, sv, 0, 0, "clicking the track above the thumb")
+	h.Click(22, 11)
+	assertScroll(t, sv, 20, 0, "clicking the track right of the thumb")
+
+	h.Drag(23, 3, 23, 10)
+	assertScroll(t, sv, 20, 42, "dragging the vertical thumb to the bottom")
+	h.AssertRect(win, 2, 2, 22, 10) // Not resized by dragging in the border.
+	h.Drag(23, 10, 23, 0)           // Past the top, still captured.
+	assertScroll(t, sv, 20, 0, "dragging the vertical thumb past the top")
+	sv.ScrollTo(0, 0)
+	h.Drag(3, 11, 40, 11)
+	assertScroll(t, sv, 40, 0, "dragging the horizontal thumb past the end")
+}
+
+func TestScrollViewKeys(t *testing.T) {
+	h := tuixtest.New(t, 40, 20)
+	defer h.Close()
+	_, sv := scrollWindow(h)
+	h.Key(tcell.KeyDown, 0, tcell.ModCtrl)
+	h.Key(tcell.KeyRight, 0, tcell.ModCtrl)
+	assertScroll(t, sv, 1, 1, "ctrl+down and ctrl+right")
+	h.Key(tcell.KeyPgDn, 0, tcell.ModCtrl)
+	assertScroll(t, sv, 1, 9, "ctrl+page down")
+	h.Key(tcell.KeyEnd, 0, tcell.ModCtrl)
+	assertScroll(t, sv, 21, 9, "ctrl+end")
+	h.Key(tcell.KeyHome, 0, tcell.ModCtrl)
+	h.Key(tcell.KeyPgUp, 0, tcell.ModCtrl)
+	h.Key(tcell.KeyUp, 0, tcell.ModCtrl)
+	h.Key(tcell.KeyLeft, 0, tcell.ModCtrl)
+	assertScroll(t, sv, 0, 0, "ctrl+home, page up, up and left")
+	h.Key(tcell.KeyDown, 0, tcell.ModNone)
+	assertScroll(t, sv, 0, 0, "down without ctrl")
+}
+
+func TestScrollViewClamp(t *testing.T) {
+	h := tuixtest.New(t, 80, 30)
+	defer h.Close()
+	win, sv := scrollWindow(h)
+	sv.ScrollTo(100, 100)
+	assertScroll(t, sv, 40, 42, "scrolling past the end")
+	win.SetRect(2, 2, 32, 20)
+	h.Draw()
+	assertScroll(t, sv, 30, 32, "growing the window")
+	win.SetRect(2, 2, 72, 22)
+	h.Draw()
+	assertScroll(t, sv, 0, 30, "growing the window wider than the child")
+	sv.SetVirtualSize(0, 10)
+	assertScroll(t, sv, 0, 0, "shrinking the virtual size")
+}
+
+func TestScrollViewInline(t *testing.T) {
+	h := tuixtest.New(t, 40, 20)
+	defer h.Close()
+	_, inBorder := scrollWindow(h)
+	if _, _, w, h := inBorder.GetViewRect(); w != 20 || h != 8 {
+		t.Errorf("view in the border is %dx%d, expected the whole 20x8", w, h)
+	}
+
+	// Not in a window's border, the scrollbars take space from the view.
+	sv := tuix.NewScrollView(tview.NewBox()).SetVirtualSize(0, 50)
+	sv.SetRect(25, 2, 10, 6)
+	h.Desktop.SetClient(sv, false)
+	h.Draw()
+	if x, y, w, h := sv.GetViewRect(); x != 25 || y != 2 || w != 9 || h != 6 {
+		t.Errorf("view is (%d, %d) %dx%d, expected a vertical scrollbar only", x, y, w, h)
+	}
+	wheel(h, 28, 4, tcell.WheelDown, tcell.ModNone)
+	assertScroll(t, sv, 0, 1, "wheel down in the inline view")
+	h.Click(34, 7)
+	assertScroll(t, sv, 0, 7, "clicking the inline track")
+
+	sv.SetVirtualSize(30, 50)
+	h.Draw()
+	if _, _, w, h := sv.GetViewRect(); w != 9 || h != 5 {
+		t.Errorf("view is %dx%d, expected both scrollbars", w, h)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// selection is text selected in a window by dragging with a modifier,
+// read from what the window drew, like the copy mode of terminal multiplexers.
+// Positions are relative to the window's inner rect, so it moves with the window.
+type selection struct {
+	win            *Window
+	rect           bool // Rectangular, otherwise linear like text.
+	startX, startY int
+	endX, endY     int
+	drawn          *Snapshot // The window's inner rect when it was last drawn.
+}
+
+// SetSelectionModifiers sets the modifiers which select text when dragging over a window,
+// linearly like text or as a rectangle; 0 disables that kind of selection.
+// The default is shift for linear and alt for rectangular.
+// Releasing the mouse copies the selection to the clipboard.
+func (d *Desktop) SetSelectionModifiers(linear, rect tcell.ModMask) *Desktop {
+	d.selLinearMod, d.selRectMod = linear, rect
+	return d
+}
+
+// ClearSelection removes the selection.
+func (d *Desktop) ClearSelection() *Desktop {
+	d.sel = nil
+	d.selecting = false
+	return d
+}
+
+// HasSelection returns true if text is selected.
+func (d *Desktop) HasSelection() bool {
+	return d.sel != nil && d.sel.win.desktop == d
+}
+
+// selected returns true if the position relative to the window's inner rect is selected.
+func (sel *selection) selected(x, y int) bool {
+	x1, y1, x2, y2 := sel.startX, sel.startY, sel.endX, sel.endY
+	if sel.rect {
+		if x1 > x2 {
+			x1, x2 = x2, x1
+		}
+		if y1 > y2 {
+			y1, y2 = y2, y1
+		}
+		return x >= x1 && x <= x2 && y >= y1 && y <= y2
+	}
+	if y1 > y2 || (y1 == y2 && x1 > x2) {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
+	if y < y1 || y > y2 {
+		return false
+	}
+	return (y > y1 || x >= x1) && (y < y2 || x <= x2)
+}
+
+// GetSelectionText gets the selected text as the window drew it, or an empty string.
+// Windows above the selected window don't affect it.
+func (d *Desktop) GetSelectionText() string {
+	if !d.HasSelection() || d.sel.drawn == nil {
+		return ""
+	}
+	sel := d.sel
+	drawn := sel.drawn
+	var lines []string
+	for y := 0; y < drawn.Height; y++ {
+		var line strings.Builder
+		found := false
+		for x := 0; x < drawn.Width; x++ {
+			cell := drawn.Cell(x, y)
+			if cell.Rune != 0 && sel.selected(x, y) {
+				found = true
+				line.WriteRune(cell.Rune)
+				for _, r := range cell.Comb {
+					line.WriteRune(r)
+				}
+			}
+		}
+		if found {
+			lines = append(lines, strings.TrimRight(line.String(), " "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CopySelection copies the selected text to the clipboard, returns false if there's none.
+func (d *Desktop) CopySelection() bool {
+	text := d.GetSelectionText()
+	if text == "" {
+		return false
+	}
+	d.Clipboard().Set(text)
+	return true
+}
+
+// selectionMouse starts, extends and ends the selection; returns false if not selecting.
+func (d *Desktop) selectionMouse(action tview.MouseAction, event *tcell.EventMouse) (consumed bool, capture tview.Primitive) {
+	if d.selecting {
+		sel := d.sel
+		atX, atY := d.windowEvent(sel.win, event).Position()
+		inX, inY, inW, inH := sel.win.GetInnerRect()
+		sel.endX, sel.endY = clamp(atX-inX, 0, inW-1), clamp(atY-inY, 0, inH-1)
+		if action == tview.MouseLeftUp {
+			d.selecting = false
+			d.CopySelection()
+			return true, nil
+		}
+		return true, d
+	}
+	if action != tview.MouseLeftDown {
+		return false, nil
+	}
+	mods := event.Modifiers()
+	rect := d.selRectMod != 0 && mods&d.selRectMod == d.selRectMod
+	linear := d.selLinearMod != 0 && mods&d.selLinearMod == d.selLinearMod
+	if !rect && !linear {
+		d.ClearSelection()
+		return false, nil
+	}
+	win := d.hover
+	if win == nil {
+		return false, nil
+	}
+	atX, atY := d.windowEvent(win, event).Position()
+	inX, inY, inW, inH := win.GetInnerRect()
+	if atX < inX || atY < inY || atX >= inX+inW || atY >= inY+inH {
+		return false, nil
+	}
+	d.sel = &selection{win: win, rect: rect,
+		startX: atX - inX, startY: atY - inY, endX: atX - inX, endY: atY - inY}
+	d.selecting = true
+	return true, d
+}
+
+func clamp(n, lo, hi int) int {
+	if n > hi {
+		n = hi
+	}
+	if n < lo {
+		n = lo
+	}
+	return n
+}
+
+// drawSelection keeps what the selected window drew for the selection's text,
+// and highlights the selected cells; call it right after drawing the window.
+func (d *Desktop) drawSelection(screen tcell.Screen) {
+	sel := d.sel
+	if sel.win.state == Minimized {
+		return
+	}
+	inX, inY, inW, inH := sel.win.GetInnerRect()
+	sel.drawn = ScreenSnapshot(screen, inX, inY, inW, inH)
+	for y := 0; y < inH; y++ {
+		for x := 0; x < inW; x++ {
+			if sel.selected(x, y) {
+				c, combc, style, _ := screen.GetContent(inX+x, inY+y)
+				_, _, attrs := style.Decompose()
+				screen.SetContent(inX+x, inY+y, c, combc, style.Reverse(attrs&tcell.AttrReverse == 0))
+			}
+		}
+	}
+}
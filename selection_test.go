@@ -0,0 +1,56 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+// textWindow adds a window showing the text to the harness's desktop.
+func textWindow(h *tuixtest.Harness, title, text string, x, y, width, height int) *tuix.Window {
+	win := tuix.NewWindow()
+	win.SetTitle(title)
+	win.SetBorder(true).SetRect(x, y, width, height)
+	win.SetClient(tview.NewTextView().SetText(text), true)
+	h.Desktop.AddWindow(win)
+	h.Draw()
+	return win
+}
+
+func TestSelectionUnderWindow(t *testing.T) {
+	h := tuixtest.New(t, 40, 12)
+	defer h.Close()
+	line := strings.Repeat("A", 18)
+	textWindow(h, "A", line+"\n"+line+"\n"+line, 0, 0, 20, 6)
+	textWindow(h, "B", strings.Repeat("B", 10), 8, 1, 14, 4)
+
+	h.DragButton(tcell.Button1, tcell.ModShift, 1, 1, 18, 2)
+	want := line + "\n" + line
+	if text := h.Desktop.GetSelectionText(); text != want {
+		t.Errorf("selection is %q, expected %q", text, want)
+	}
+	if text := h.Desktop.Clipboard().Get(); text != want {
+		t.Errorf("clipboard is %q, expected %q", text, want)
+	}
+
+	h.DragButton(tcell.Button1, tcell.ModAlt, 3, 2, 12, 3)
+	want = "AAAAAAAAAA\nAAAAAAAAAA"
+	if text := h.Desktop.Clipboard().Get(); text != want {
+		t.Errorf("rect clipboard is %q, expected %q", text, want)
+	}
+
+	h.Click(2, 2)
+	if h.Desktop.HasSelection() {
+		t.Error("click did not clear the selection")
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix/internal/driver"
+	"github.com/rivo/tview"
+)
+
+// MovePolicy decides if the session may move, resize or change the state of the window.
+type MovePolicy func(sess *SharedSession, win *Window) bool
+
+// OwnerMovePolicy only lets the owner of a window move it, see SharedDesktop.SetOwner.
+// Windows without an owner can be moved by anyone.
+func OwnerMovePolicy(sess *SharedSession, win *Window) bool {
+	owner := sess.shared.owners[win]
+	return owner == nil || owner == sess
+}
+
+// SharedDesktop lets several sessions, each with its own screen, use one desktop,
+// such as for pairing. Each session has its own focus, cursor and mouse pointer,
+// with its own hover, drags and selection;
+// the other sessions' pointers are shown as markers in their colors.
+// The desktop is sized to fit the smallest screen.
+// The desktop has no application, so don't change it directly from other goroutines, use Update.
+type SharedDesktop struct {
+	mu       sync.Mutex
+	desktop  *Desktop
+	sessions []*SharedSession
+	policy   MovePolicy
+	owners   map[*Window]*SharedSession
+	active   *SharedSession // Session whose focus is applied.
+}
+
+// NewSharedDesktop creates a shared desktop, sessions are added with Attach.
+func NewSharedDesktop(d *Desktop) *SharedDesktop {
+	sd := &SharedDesktop{
+		desktop: d,
+		owners:  make(map[*Window]*SharedSession),
+	}
+	d.movable = func(win *Window) bool {
+		// Called while handling the active session's event.
+		return sd.policy == nil || sd.active == nil || sd.policy(sd.active, win)
+	}
+	d.redraw = sd.Draw
+	d.pointers = nil // The sessions' pointers.
+	return sd
+}
+
+// GetDesktop gets the shared desktop.
+func (sd *SharedDesktop) GetDesktop() *Desktop {
+	return sd.desktop
+}
+
+// SetMovePolicy sets who may move which window, nil lets anyone move any window.
+func (sd *SharedDesktop) SetMovePolicy(policy MovePolicy) *SharedDesktop {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	sd.policy = policy
+	return sd
+}
+
+// SetOwner sets the session which owns the window, or nil; see OwnerMovePolicy.
+func (sd *SharedDesktop) SetOwner(win *Window, sess *SharedSession) *SharedDesktop {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	if sess == nil {
+		delete(sd.owners, win)
+	} else {
+		sd.owners[win] = sess
+	}
+	return sd
+}
+
+// GetOwner gets the session which owns the window, or nil.
+func (sd *SharedDesktop) GetOwner(win *Window) *SharedSession {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return sd.owners[win]
+}
+
+// GetSessions gets the attached sessions.
+func (sd *SharedDesktop) GetSessions() []*SharedSession {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return append([]*SharedSession(nil), sd.sessions...)
+}
+
+// Attach adds a session for the screen, which must already be initialized.
+// The name and color are shown to the other sessions; use Run to handle the screen's events.
+func (sd *SharedDesktop) Attach(screen tcell.Screen, name string, color tcell.Color) *SharedSession {
+	sess := &SharedSession{
+		shared:   sd,
+		screen:   screen,
+		name:     name,
+		color:    color,
+		drv:      driver.New(sd.desktop),
+		pointerX: -1,
+		pointerY: -1,
+	}
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	sd.sessions = append(sd.sessions, sess)
+	sd.desktop.pointers = append(sd.desktop.pointers, &sess.pointer)
+	sd.activate(sess)
+	sess.drv.SetFocus(sd.desktop)
+	sd.draw()
+	return sess
+}
+
+// detach removes the session, its windows no longer have an owner.
+func (sd *SharedDesktop) detach(sess *SharedSession) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	for i, xsess := range sd.sessions {
+		if xsess == sess {
+			copy(sd.sessions[i:], sd.sessions[i+1:])
+			sd.sessions = sd.sessions[:len(sd.sessions)-1]
+			break
+		}
+	}
+	pointers := sd.desktop.pointers
+	for i, p := range pointers {
+		if p == &sess.pointer {
+			copy(pointers[i:], pointers[i+1:])
+			sd.desktop.pointers = pointers[:len(pointers)-1]
+			break
+		}
+	}
+	for win, owner := range sd.owners {
+		if owner == sess {
+			delete(sd.owners, win)
+		}
+	}
+	if sd.active == sess {
+		sess.drv.Blur()
+		sd.active = nil
+		sd.desktop.pointerState = &pointerState{} // No session's.
+	}
+	sd.draw()
+}
+
+// activate applies the session's focus and pointer to the desktop, so it can handle events and draw.
+func (sd *SharedDesktop) activate(sess *SharedSession) {
+	if sd.active == sess {
+		return
+	}
+	if sd.active != nil {
+		sd.active.drv.Blur()
+	}
+	sd.active = sess
+	sd.desktop.pointerState = &sess.pointer
+	sess.drv.Refocus()
+}
+
+// Update calls f to change the desktop from any goroutine, then draws every session.
+func (sd *SharedDesktop) Update(f func()) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	f()
+	sd.draw()
+}
+
+// Draw draws every session, it's safe to call from any goroutine.
+func (sd *SharedDesktop) Draw() {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	sd.draw()
+}
+
+func (sd *SharedDesktop) draw() {
+	if len(sd.sessions) == 0 {
+		return
+	}
+	w, h := sd.sessions[0].screen.Size()
+	for _, sess := range sd.sessions[1:] {
+		sw, sh := sess.screen.Size()
+		if sw < w {
+			w = sw
+		}
+		if sh < h {
+			h = sh
+		}
+	}
+	if x, y, dw, dh := sd.desktop.GetRect(); x != 0 || y != 0 || dw != w || dh != h {
+		sd.desktop.SetRect(0, 0, w, h)
+	}
+	active := sd.active
+	for _, sess := range sd.sessions {
+		sd.activate(sess)
+		screen := sess.screen
+		screen.HideCursor()
+		screen.Clear()
+		sd.desktop.Draw(screen)
+		for _, other := range sd.sessions {
+			if other != sess && other.pointerX >= 0 {
+				drawPointer(screen, other.pointerX, other.pointerY, other.color)
+			}
+		}
+		screen.Show()
+	}
+	if active != nil {
+		sd.activate(active)
+	}
+}
+
+// drawPointer marks the cell with the color, keeping what's there.
+func drawPointer(screen tcell.Screen, x, y int, color tcell.Color) {
+	c, combc, style, _ := screen.GetContent(x, y)
+	if c == ' ' || c == 0 {
+		c = '▲'
+	}
+	screen.SetContent(x, y, c, combc, style.Background(color).Foreground(tcell.ColorBlack))
+}
+
+// SharedSession is one session of a SharedDesktop.
+type SharedSession struct {
+	shared   *SharedDesktop
+	screen   tcell.Screen
+	name     string
+	color    tcell.Color
+	drv      *driver.Driver
+	pointerX int
+	pointerY int
+	pointer  pointerState // Hover, drags and selection.
+	pc       pasteCollector
+}
+
+// GetName gets the name of the session, such as the user's name.
+func (sess *SharedSession) GetName() string {
+	return sess.name
+}
+
+// GetColor gets the color of the session's pointer.
+func (sess *SharedSession) GetColor() tcell.Color {
+	return sess.color
+}
+
+// GetScreen gets the session's screen.
+func (sess *SharedSession) GetScreen() tcell.Screen {
+	return sess.screen
+}
+
+// GetPointer gets the position of the session's mouse pointer, or -1, -1 if it's not known.
+func (sess *SharedSession) GetPointer() (x, y int) {
+	sd := sess.shared
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return sess.pointerX, sess.pointerY
+}
+
+// GetFocus gets the primitive focused in this session.
+func (sess *SharedSession) GetFocus() tview.Primitive {
+	sd := sess.shared
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return sess.drv.GetFocus()
+}
+
+// SetFocus focuses the primitive in this session only.
+func (sess *SharedSession) SetFocus(p tview.Primitive) {
+	sd := sess.shared
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	sd.activate(sess)
+	sess.drv.SetFocus(p)
+	sd.draw()
+}
+
+// HandleEvent handles a key, mouse, paste or resize event from the session's screen,
+// then draws every session; bracketed pastes go to the desktop's Paste. Run calls this, it can also be used to inject events.
+func (sess *SharedSession) HandleEvent(event tcell.Event) {
+	sd := sess.shared
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	sd.activate(sess)
+	if consumed, text, done := sess.pc.collect(event); consumed {
+		if done && text != "" {
+			sd.desktop.Paste(text)
+			sd.draw()
+		}
+		return
+	}
+	switch event := event.(type) {
+	case *tcell.EventMouse:
+		sess.pointerX, sess.pointerY = event.Position()
+		sess.drv.HandleEvent(event)
+	case *tcell.EventResize:
+		sess.screen.Sync()
+	default:
+		sess.drv.HandleEvent(event)
+	}
+	sd.draw()
+}
+
+// Run handles the events of the session's screen until Close.
+func (sess *SharedSession) Run() error {
+	defer sess.shared.detach(sess)
+	for {
+		event := sess.screen.PollEvent()
+		if event == nil {
+			return nil // Fini
+		}
+		sess.HandleEvent(event)
+	}
+}
+
+// Close detaches the session and finalizes its screen, which ends Run.
+func (sess *SharedSession) Close() {
+	sess.shared.detach(sess)
+	sess.screen.Fini()
+}
@@ -0,0 +1,229 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+func simScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(width, height)
+	return screen
+}
+
+// reversed returns true if any cell of the screen is drawn reversed, such as selected.
+func reversed(screen tcell.Screen) bool {
+	w, h := screen.Size()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			_, _, style, _ := screen.GetContent(x, y)
+			if _, _, attrs := style.Decompose(); attrs&tcell.AttrReverse != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestSharedPointers(t *testing.T) {
+	d := tuix.NewDesktop()
+	sd := tuix.NewSharedDesktop(d)
+	screen1, screen2 := simScreen(t, 40, 12), simScreen(t, 40, 12)
+	defer screen1.Fini()
+	line := strings.Repeat("A", 14)
+	enters, leaves := 0, 0
+	var a, b *tuix.Window
+	sd.Update(func() {
+		a = tuix.NewWindow()
+		a.SetTitle("A")
+		a.SetBorder(true).SetRect(0, 0, 16, 6)
+		a.SetClient(tview.NewTextView().SetText(line+"\n"+line), true)
+		a.SetMouseEnterFunc(func() { enters++ }).SetMouseLeaveFunc(func() { leaves++ })
+		d.AddWindow(a)
+		b = tuix.NewWindow()
+		b.SetTitle("B")
+		b.SetBorder(true).SetRect(20, 0, 16, 6)
+		b.SetClient(tview.NewTextView(), true)
+		d.AddWindow(b)
+	})
+	sess1 := sd.Attach(screen1, "one", tcell.ColorRed)
+	sess2 := sd.Attach(screen2, "two", tcell.ColorBlue)
+
+	// Session one selects in A while session two moves over B.
+	sess1.HandleEvent(tcell.NewEventMouse(2, 1, tcell.ButtonNone, 0))
+	sess1.HandleEvent(tcell.NewEventMouse(2, 1, tcell.Button1, tcell.ModShift))
+	sess2.HandleEvent(tcell.NewEventMouse(25, 2, tcell.ButtonNone, 0))
+	sess1.HandleEvent(tcell.NewEventMouse(6, 2, tcell.Button1, tcell.ModShift))
+	sess2.HandleEvent(tcell.NewEventMouse(26, 3, tcell.ButtonNone, 0))
+	sess1.HandleEvent(tcell.NewEventMouse(6, 2, tcell.ButtonNone, tcell.ModShift))
+	if enters != 1 || leaves != 0 {
+		t.Errorf("A was entered %d and left %d times, expected once and never", enters, leaves)
+	}
+	want := line[1:] + "\n" + line[:6]
+	if text := d.Clipboard().Get(); text != want {
+		t.Errorf("clipboard is %q, expected %q", text, want)
+	}
+	if !reversed(screen1) {
+		t.Error("session one's selection is not drawn on its screen")
+	}
+	if reversed(screen2) {
+		t.Error("session one's selection is drawn on session two's screen")
+	}
+
+	// Session two's click clears only its own selection.
+	sess2.HandleEvent(tcell.NewEventMouse(26, 3, tcell.Button1, 0))
+	sess2.HandleEvent(tcell.NewEventMouse(26, 3, tcell.ButtonNone, 0))
+	if !reversed(screen1) {
+		t.Error("session two's click cleared session one's selection")
+	}
+	sess2.Close()
+	if !reversed(screen1) {
+		t.Error("closing session two cleared session one's selection")
+	}
+	sd.Update(func() { d.RemoveWindow(a) })
+	if leaves != 1 {
+		t.Errorf("A was left %d times after removing it, expected once", leaves)
+	}
+}
+
+// sharedWindows adds windows A at (0, 0) and B at (20, 0) with the clients, and attaches two sessions.
+func sharedWindows(t *testing.T, sd *tuix.SharedDesktop, clientA, clientB tview.Primitive) (a, b *tuix.Window, sess1, sess2 *tuix.SharedSession) {
+	sd.Update(func() {
+		a = tuix.NewWindow().SetResizable(true)
+		a.SetTitle("A")
+		a.SetBorder(true).SetRect(0, 0, 16, 6)
+		a.SetClient(clientA, true)
+		sd.GetDesktop().AddWindow(a)
+		b = tuix.NewWindow().SetResizable(true)
+		b.SetTitle("B")
+		b.SetBorder(true).SetRect(20, 0, 16, 6)
+		b.SetClient(clientB, true)
+		sd.GetDesktop().AddWindow(b)
+	})
+	sess1 = sd.Attach(simScreen(t, 40, 12), "one", tcell.ColorRed)
+	sess2 = sd.Attach(simScreen(t, 40, 12), "two", tcell.ColorBlue)
+	return
+}
+
+// sessionDrag drags with the left button in the session.
+func sessionDrag(sess *tuix.SharedSession, fromX, fromY, toX, toY int) {
+	sess.HandleEvent(tcell.NewEventMouse(fromX, fromY, tcell.ButtonNone, 0))
+	sess.HandleEvent(tcell.NewEventMouse(fromX, fromY, tcell.Button1, 0))
+	sess.HandleEvent(tcell.NewEventMouse(toX, toY, tcell.Button1, 0))
+	sess.HandleEvent(tcell.NewEventMouse(toX, toY, tcell.ButtonNone, 0))
+}
+
+// sessionClick clicks the left button in the session, twice for a double click.
+func sessionClick(sess *tuix.SharedSession, x, y, clicks int) {
+	sess.HandleEvent(tcell.NewEventMouse(x, y, tcell.ButtonNone, 0))
+	for i := 0; i < clicks; i++ {
+		sess.HandleEvent(tcell.NewEventMouse(x, y, tcell.Button1, 0))
+		sess.HandleEvent(tcell.NewEventMouse(x, y, tcell.ButtonNone, 0))
+	}
+}
+
+func assertWindowRect(t *testing.T, win *tuix.Window, x, y, width, height int, what string) {
+	t.Helper()
+	if wx, wy, ww, wh := win.GetRect(); wx != x || wy != y || ww != width || wh != height {
+		t.Errorf("%s: window is (%d, %d) %dx%d, expected (%d, %d) %dx%d", what, wx, wy, ww, wh, x, y, width, height)
+	}
+}
+
+func TestSharedOwnerMovePolicy(t *testing.T) {
+	sd := tuix.NewSharedDesktop(tuix.NewDesktop())
+	a, b, sess1, sess2 := sharedWindows(t, sd, tview.NewBox(), tview.NewBox())
+	defer sess2.Close()
+	sd.SetMovePolicy(tuix.OwnerMovePolicy).SetOwner(a, sess1)
+	if owner := sd.GetOwner(a); owner != sess1 {
+		t.Errorf("A is owned by %v", owner)
+	}
+
+	sessionDrag(sess2, 5, 0, 5, 3)
+	assertWindowRect(t, a, 0, 0, 16, 6, "other session moving A")
+	sessionDrag(sess2, 15, 5, 18, 8)
+	assertWindowRect(t, a, 0, 0, 16, 6, "other session resizing A")
+	sessionClick(sess2, 5, 0, 2)
+	if state := a.GetState(); state != tuix.Restored {
+		t.Errorf("other session changed A to %v", state)
+	}
+	sessionDrag(sess2, 25, 0, 25, 4)
+	assertWindowRect(t, b, 20, 4, 16, 6, "other session moving B without an owner")
+
+	sessionDrag(sess1, 5, 0, 7, 3)
+	assertWindowRect(t, a, 2, 3, 16, 6, "owner moving A")
+
+	// Closing the owner's session frees its windows.
+	sess1.Close()
+	if owner := sd.GetOwner(a); owner != nil {
+		t.Errorf("A is owned by %v after closing its owner", owner)
+	}
+	sessionDrag(sess2, 5, 3, 5, 5)
+	assertWindowRect(t, a, 2, 5, 16, 6, "other session moving A after closing its owner")
+}
+
+func TestSharedMovePolicy(t *testing.T) {
+	sd := tuix.NewSharedDesktop(tuix.NewDesktop())
+	a, _, sess1, sess2 := sharedWindows(t, sd, tview.NewBox(), tview.NewBox())
+	defer sess1.Close()
+	defer sess2.Close()
+	sd.SetMovePolicy(func(sess *tuix.SharedSession, win *tuix.Window) bool {
+		return sess.GetName() != "two"
+	})
+	sessionDrag(sess2, 5, 0, 5, 3)
+	assertWindowRect(t, a, 0, 0, 16, 6, "session two moving A")
+	sessionDrag(sess1, 5, 0, 5, 3)
+	assertWindowRect(t, a, 0, 3, 16, 6, "session one moving A")
+	sd.SetMovePolicy(nil)
+	sessionDrag(sess2, 5, 3, 5, 4)
+	assertWindowRect(t, a, 0, 4, 16, 6, "session two moving A without a policy")
+}
+
+func TestSharedFocus(t *testing.T) {
+	sd := tuix.NewSharedDesktop(tuix.NewDesktop())
+	inputA, inputB := tview.NewInputField(), tview.NewInputField()
+	a, b, sess1, sess2 := sharedWindows(t, sd, inputA, inputB)
+	defer sess1.Close()
+	defer sess2.Close()
+	sessionClick(sess1, 5, 1, 1)
+	sessionClick(sess2, 25, 1, 1)
+	for _, r := range "one" {
+		sess1.HandleEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	for _, r := range "two" {
+		sess2.HandleEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	sess1.HandleEvent(tcell.NewEventKey(tcell.KeyRune, '!', tcell.ModNone))
+	if text := inputA.GetText(); text != "one!" {
+		t.Errorf("A has %q, expected session one's typing", text)
+	}
+	if text := inputB.GetText(); text != "two" {
+		t.Errorf("B has %q, expected session two's typing", text)
+	}
+	if focus := sess1.GetFocus(); focus != inputA {
+		t.Errorf("session one's focus is %v, expected A's input", focus)
+	}
+
+	// Focusing in one session doesn't change the other's.
+	sess2.SetFocus(a)
+	if focus := sess1.GetFocus(); focus != inputA {
+		t.Errorf("session one's focus is %v after session two focused A", focus)
+	}
+	sess1.SetFocus(b)
+	sess1.HandleEvent(tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModNone))
+	if text := inputB.GetText(); text != "two?" {
+		t.Errorf("B has %q after session one focused it", text)
+	}
+}
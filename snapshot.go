@@ -0,0 +1,242 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// SnapshotCell is a cell of a Snapshot.
+type SnapshotCell struct {
+	Rune  rune   // Space if empty, 0 if covered by the wide rune before it.
+	Comb  []rune // Combining runes.
+	Style tcell.Style
+}
+
+// Snapshot is the cells of a drawn screen, see Desktop.Snapshot.
+type Snapshot struct {
+	Width  int
+	Height int
+	Cells  []SnapshotCell // Width*Height cells, row by row.
+}
+
+// Snapshot draws the desktop offscreen at its current size, and gets its cells.
+// It's drawn with the colors of the screen it was last drawn on,
+// and notifications which timed out are kept until it's next drawn.
+// Set the desktop's rect first if it hasn't been drawn yet;
+// like the first Draw, that initializes the windows.
+// Like other changes to the desktop, call it from the application's goroutine.
+func (d *Desktop) Snapshot() *Snapshot {
+	x, y, w, h := d.GetRect()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		return &Snapshot{}
+	}
+	defer screen.Fini()
+	screen.SetSize(x+w, y+h)
+	d.offscreen = true
+	defer func() { d.offscreen = false }()
+	d.Draw(screen)
+	return ScreenSnapshot(screen, x, y, w, h)
+}
+
+// ScreenSnapshot gets the cells of the screen in the rect.
+func ScreenSnapshot(screen tcell.Screen, x, y, width, height int) *Snapshot {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	snap := &Snapshot{Width: width, Height: height, Cells: make([]SnapshotCell, width*height)}
+	for cy := 0; cy < height; cy++ {
+		for cx := 0; cx < width; cx++ {
+			c, comb, style, cw := screen.GetContent(x+cx, y+cy)
+			if c == 0 {
+				c = ' '
+			}
+			snap.Cells[cy*width+cx] = SnapshotCell{Rune: c, Comb: comb, Style: style}
+			for i := 1; i < cw && cx+i < width; i++ {
+				snap.Cells[cy*width+cx+i] = SnapshotCell{Style: style}
+			}
+			if cw > 1 {
+				cx += cw - 1
+			}
+		}
+	}
+	return snap
+}
+
+// Cell gets the cell at the position, or an empty cell if outside.
+func (snap *Snapshot) Cell(x, y int) SnapshotCell {
+	if x < 0 || y < 0 || x >= snap.Width || y >= snap.Height {
+		return SnapshotCell{Rune: ' '}
+	}
+	return snap.Cells[y*snap.Width+x]
+}
+
+// lines calls f for each line with its cells, skipping the ones covered by wide runes.
+func (snap *Snapshot) lines(f func(y int, cells []SnapshotCell)) {
+	for y := 0; y < snap.Height; y++ {
+		row := snap.Cells[y*snap.Width : (y+1)*snap.Width]
+		cells := make([]SnapshotCell, 0, len(row))
+		for _, cell := range row {
+			if cell.Rune != 0 {
+				cells = append(cells, cell)
+			}
+		}
+		f(y, cells)
+	}
+}
+
+// Text gets the snapshot as plain text, one line per row without trailing spaces.
+func (snap *Snapshot) Text() string {
+	var sb strings.Builder
+	snap.lines(func(y int, cells []SnapshotCell) {
+		var line strings.Builder
+		for _, cell := range cells {
+			line.WriteRune(cell.Rune)
+			for _, r := range cell.Comb {
+				line.WriteRune(r)
+			}
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " "))
+		sb.WriteByte('\n')
+	})
+	return sb.String()
+}
+
+// ANSI gets the snapshot as text with ANSI escape sequences for the styles,
+// such as to print to a terminal or attach to a bug report.
+func (snap *Snapshot) ANSI() string {
+	var sb strings.Builder
+	snap.lines(func(y int, cells []SnapshotCell) {
+		var last tcell.Style
+		for i, cell := range cells {
+			if i == 0 || cell.Style != last {
+				sb.WriteString(ansiStyle(cell.Style))
+				last = cell.Style
+			}
+			sb.WriteRune(cell.Rune)
+			for _, r := range cell.Comb {
+				sb.WriteRune(r)
+			}
+		}
+		sb.WriteString("\x1b[0m\n")
+	})
+	return sb.String()
+}
+
+func ansiStyle(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	s := "\x1b[0"
+	for _, a := range []struct {
+		attr tcell.AttrMask
+		code string
+	}{
+		{tcell.AttrBold, "1"},
+		{tcell.AttrDim, "2"},
+		{tcell.AttrItalic, "3"},
+		{tcell.AttrUnderline, "4"},
+		{tcell.AttrBlink, "5"},
+		{tcell.AttrReverse, "7"},
+		{tcell.AttrStrikeThrough, "9"},
+	} {
+		if attrs&a.attr != 0 {
+			s += ";" + a.code
+		}
+	}
+	s += ansiColor(fg, "38") + ansiColor(bg, "48")
+	return s + "m"
+}
+
+func ansiColor(color tcell.Color, code string) string {
+	switch {
+	case color == tcell.ColorDefault || !color.Valid():
+		return ""
+	case color.IsRGB():
+		r, g, b := color.RGB()
+		return fmt.Sprintf(";%s;2;%d;%d;%d", code, r, g, b)
+	default:
+		return fmt.Sprintf(";%s;5;%d", code, color-tcell.ColorValid)
+	}
+}
+
+// HTML gets the snapshot as an HTML pre element with inline styles,
+// such as for documentation.
+func (snap *Snapshot) HTML() string {
+	var sb strings.Builder
+	sb.WriteString(`<pre style="font-family: monospace; line-height: 1; background-color: #000000; color: #c0c0c0">`)
+	snap.lines(func(y int, cells []SnapshotCell) {
+		if y > 0 {
+			sb.WriteByte('\n')
+		}
+		for i := 0; i < len(cells); {
+			j := i + 1
+			for j < len(cells) && cells[j].Style == cells[i].Style {
+				j++
+			}
+			var text strings.Builder
+			for _, cell := range cells[i:j] {
+				text.WriteRune(cell.Rune)
+				for _, r := range cell.Comb {
+					text.WriteRune(r)
+				}
+			}
+			if css := htmlStyle(cells[i].Style); css != "" {
+				fmt.Fprintf(&sb, `<span style="%s">%s</span>`, css, html.EscapeString(text.String()))
+			} else {
+				sb.WriteString(html.EscapeString(text.String()))
+			}
+			i = j
+		}
+	})
+	sb.WriteString("</pre>\n")
+	return sb.String()
+}
+
+func htmlStyle(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	if attrs&tcell.AttrReverse != 0 {
+		fg, bg = bg, fg
+		if fg == tcell.ColorDefault {
+			fg = tcell.ColorBlack
+		}
+		if bg == tcell.ColorDefault {
+			bg = tcell.ColorSilver
+		}
+	}
+	var css []string
+	if fg != tcell.ColorDefault && fg.Valid() {
+		css = append(css, fmt.Sprintf("color: #%06x", fg.Hex()))
+	}
+	if bg != tcell.ColorDefault && bg.Valid() {
+		css = append(css, fmt.Sprintf("background-color: #%06x", bg.Hex()))
+	}
+	if attrs&tcell.AttrBold != 0 {
+		css = append(css, "font-weight: bold")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		css = append(css, "opacity: 0.7")
+	}
+	if attrs&tcell.AttrItalic != 0 {
+		css = append(css, "font-style: italic")
+	}
+	switch {
+	case attrs&tcell.AttrUnderline != 0 && attrs&tcell.AttrStrikeThrough != 0:
+		css = append(css, "text-decoration: underline line-through")
+	case attrs&tcell.AttrUnderline != 0:
+		css = append(css, "text-decoration: underline")
+	case attrs&tcell.AttrStrikeThrough != 0:
+		css = append(css, "text-decoration: line-through")
+	}
+	return strings.Join(css, "; ")
+}
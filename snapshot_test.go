@@ -0,0 +1,89 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+)
+
+// colorScreen is a screen with fewer colors.
+type colorScreen struct {
+	tcell.Screen
+	colors int
+}
+
+func (cs *colorScreen) Colors() int {
+	return cs.colors
+}
+
+func TestSnapshotKeepsState(t *testing.T) {
+	screen := &colorScreen{Screen: simScreen(t, 40, 12), colors: 8}
+	defer screen.Fini()
+	d := tuix.NewDesktop()
+	d.SetRect(0, 0, 40, 12)
+	win := tuix.NewWindow()
+	win.SetTitle("A").SetBorder(true).SetRect(2, 2, 20, 6)
+	d.AddWindow(win)
+	d.Draw(screen)
+	drawn := tuix.ScreenSnapshot(screen, 0, 0, 40, 12)
+	if snap := d.Snapshot(); !reflect.DeepEqual(snap, drawn) {
+		t.Errorf("snapshot is not drawn with the screen's colors:\n%s\nexpected:\n%s", snap.ANSI(), drawn.ANSI())
+	}
+	d.Draw(screen)
+	if again := tuix.ScreenSnapshot(screen, 0, 0, 40, 12); !reflect.DeepEqual(again, drawn) {
+		t.Error("drawing changed after a snapshot")
+	}
+
+	d.Notify("Saved", "", &tuix.NotifyOptions{Timeout: time.Millisecond})
+	time.Sleep(5 * time.Millisecond)
+	if text := d.Snapshot().Text(); !strings.Contains(text, "Saved") {
+		t.Errorf("snapshot expired the notification:\n%s", text)
+	}
+	d.Draw(screen)
+	if text := tuix.ScreenSnapshot(screen, 0, 0, 40, 12).Text(); strings.Contains(text, "Saved") {
+		t.Errorf("notification did not expire when drawn:\n%s", text)
+	}
+}
+
+func TestSnapshotFormats(t *testing.T) {
+	screen := simScreen(t, 6, 2)
+	defer screen.Fini()
+	bold := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+	screen.SetContent(0, 0, 'a', nil, bold)
+	screen.SetContent(1, 0, '世', nil, tcell.StyleDefault)
+	screen.SetContent(3, 0, '<', nil, tcell.StyleDefault)
+	screen.SetContent(0, 1, 'e', []rune{'́'}, tcell.StyleDefault.Reverse(true))
+	screen.Show()
+	snap := tuix.ScreenSnapshot(screen, 0, 0, 6, 2)
+	if cell := snap.Cell(2, 0); cell.Rune != 0 {
+		t.Errorf("cell covered by a wide rune is %q", cell.Rune)
+	}
+	if cell := snap.Cell(9, 9); cell.Rune != ' ' {
+		t.Errorf("cell outside is %q", cell.Rune)
+	}
+	if text, want := snap.Text(), "a世<\né\n"; text != want {
+		t.Errorf("text is %q, expected %q", text, want)
+	}
+	if ansi, want := snap.ANSI(), "\x1b[0;1;38;5;9ma\x1b[0m世<  \x1b[0m\n\x1b[0;7mé\x1b[0m     \x1b[0m\n"; ansi != want {
+		t.Errorf("ANSI is %q, expected %q", ansi, want)
+	}
+	html := snap.HTML()
+	for _, want := range []string{
+		`<span style="color: #ff0000; font-weight: bold">a</span>世&lt;`,
+		`<span style="color: #000000; background-color: #c0c0c0">e` + "́" + `</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML %q does not contain %q", html, want)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package sshd serves tuix desktops over SSH,
+// each session gets its own application, screen and desktop.
+package sshd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/terminfo"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrServerClosed is returned by Serve after Close.
+var ErrServerClosed = errors.New("sshd: server closed")
+
+// Server accepts SSH connections and runs a desktop for each interactive session.
+type Server struct {
+	config      *ssh.ServerConfig
+	newDesktop  func(sess *Session) *tuix.Desktop
+	shared      *tuix.SharedDesktop
+	nextColor   int
+	idleTimeout time.Duration
+	maxConns    int
+	mu          sync.Mutex
+	listeners   map[net.Listener]struct{}
+	conns       map[net.Conn]struct{}
+	closed      bool
+}
+
+// NewServer creates a server with the SSH config, which has the host keys and authentication.
+// newDesktop is called to create the desktop for each session, it can return nil to refuse;
+// the desktop is then given the session's application. If newDesktop is nil, every session is refused.
+func NewServer(config *ssh.ServerConfig, newDesktop func(sess *Session) *tuix.Desktop) *Server {
+	return &Server{
+		config:     config,
+		newDesktop: newDesktop,
+		listeners:  make(map[net.Listener]struct{}),
+		conns:      make(map[net.Conn]struct{}),
+	}
+}
+
+// NewSharedServer creates a server where every session uses the same shared desktop,
+// each user's pointer gets its own color.
+func NewSharedServer(config *ssh.ServerConfig, shared *tuix.SharedDesktop) *Server {
+	srv := NewServer(config, nil)
+	srv.shared = shared
+	return srv
+}
+
+// Pointer colors of shared sessions.
+var sessionColors = []tcell.Color{
+	tcell.ColorRed, tcell.ColorLime, tcell.ColorBlue, tcell.ColorYellow,
+	tcell.ColorFuchsia, tcell.ColorAqua, tcell.ColorOrange, tcell.ColorPurple,
+}
+
+// SetIdleTimeout disconnects clients which don't send anything for this long, 0 for never.
+func (srv *Server) SetIdleTimeout(timeout time.Duration) *Server {
+	srv.idleTimeout = timeout
+	return srv
+}
+
+// SetMaxConnections limits how many clients can be connected at once, 0 for no limit.
+// Connections over the limit are closed right away.
+func (srv *Server) SetMaxConnections(n int) *Server {
+	srv.maxConns = n
+	return srv
+}
+
+// ListenAndServe listens on the TCP address and serves connections, see Serve.
+func (srv *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// Serve accepts connections on the listener until it fails or the server is closed.
+// The listener is closed when it returns.
+func (srv *Server) Serve(l net.Listener) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	srv.listeners[l] = struct{}{}
+	srv.mu.Unlock()
+	defer func() {
+		srv.mu.Lock()
+		delete(srv.listeners, l)
+		srv.mu.Unlock()
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			srv.mu.Lock()
+			closed := srv.closed
+			srv.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				time.Sleep(50 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		if !srv.addConn(conn) {
+			conn.Close()
+			continue
+		}
+		go srv.serveConn(conn)
+	}
+}
+
+// Close closes the listeners and disconnects every client.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.closed = true
+	for l := range srv.listeners {
+		l.Close()
+	}
+	for conn := range srv.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// addConn tracks the connection, returns false if over the limit or closed.
+func (srv *Server) addConn(conn net.Conn) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.closed || (srv.maxConns > 0 && len(srv.conns) >= srv.maxConns) {
+		return false
+	}
+	srv.conns[conn] = struct{}{}
+	return true
+}
+
+func (srv *Server) removeConn(conn net.Conn) {
+	srv.mu.Lock()
+	delete(srv.conns, conn)
+	srv.mu.Unlock()
+}
+
+// idleConn times out reads, so a client which sends nothing is disconnected.
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleConn) Read(p []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Read(p)
+}
+
+func (srv *Server) serveConn(conn net.Conn) {
+	defer srv.removeConn(conn)
+	defer conn.Close()
+	var netConn net.Conn = conn
+	if srv.idleTimeout > 0 {
+		netConn = &idleConn{Conn: conn, timeout: srv.idleTimeout}
+	}
+	sshConn, chans, reqs, err := ssh.NewServerConn(netConn, srv.config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			continue
+		}
+		go srv.serveSession(sshConn, ch, chReqs)
+	}
+}
+
+// Request payloads, see RFC 4254.
+type ptyRequest struct {
+	Term   string
+	Cols   uint32
+	Rows   uint32
+	Width  uint32
+	Height uint32
+	Modes  string
+}
+
+type windowChange struct {
+	Cols   uint32
+	Rows   uint32
+	Width  uint32
+	Height uint32
+}
+
+// serveSession handles the requests of a session channel, a desktop runs on shell.
+func (srv *Server) serveSession(conn *ssh.ServerConn, ch ssh.Channel, reqs <-chan *ssh.Request) {
+	sess := &Session{conn: conn}
+	for req := range reqs {
+		ok := false
+		switch req.Type {
+		case "pty-req":
+			var pty ptyRequest
+			if ssh.Unmarshal(req.Payload, &pty) == nil && sess.tty == nil {
+				sess.term = pty.Term
+				sess.tty = newChannelTty(ch, int(pty.Cols), int(pty.Rows))
+				ok = true
+			}
+		case "window-change":
+			var wc windowChange
+			if ssh.Unmarshal(req.Payload, &wc) == nil && sess.tty != nil {
+				sess.tty.resize(int(wc.Cols), int(wc.Rows))
+				ok = true
+			}
+		case "env":
+			ok = true // Accepted but not used.
+		case "shell":
+			if sess.tty == nil {
+				fmt.Fprint(ch.Stderr(), "A terminal is required, try ssh -t\r\n")
+			} else if !sess.started {
+				sess.started = true
+				ok = true
+				go srv.runSession(sess, ch)
+			}
+		}
+		if req.WantReply {
+			req.Reply(ok, nil)
+		}
+		if req.Type == "shell" && !ok {
+			ch.Close()
+		}
+	}
+}
+
+// envMu serializes creating screens, since tcell gets the terminal type from TERM.
+// Anything else reading the environment while a screen is created can see the
+// client's TERM, such as a terminal.Start in another session.
+var envMu sync.Mutex
+
+// defaultTerm is used when the client's terminal type isn't known.
+const defaultTerm = "xterm-256color"
+
+var termPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]{0,63}$`)
+
+// screenTerm gets the terminal type to create the client's screen with.
+// Only types built into tcell are used, since tcell runs infocmp to look up others,
+// and the client can send anything.
+func screenTerm(term string) string {
+	if termPattern.MatchString(term) {
+		if _, err := terminfo.LookupTerminfo(term); err == nil {
+			return term
+		}
+	}
+	return defaultTerm
+}
+
+// newScreen creates and initializes a screen for the tty with the terminal type, see screenTerm.
+func newScreen(tty tcell.Tty, term string) (tcell.Screen, error) {
+	envMu.Lock()
+	defer envMu.Unlock()
+	oldTerm, hadTerm := os.LookupEnv("TERM")
+	os.Setenv("TERM", screenTerm(term))
+	defer func() {
+		if hadTerm {
+			os.Setenv("TERM", oldTerm)
+		} else {
+			os.Unsetenv("TERM")
+		}
+	}()
+	screen, err := tcell.NewTerminfoScreenFromTty(tty)
+	if err != nil {
+		return nil, err
+	}
+	if err := screen.Init(); err != nil {
+		return nil, err
+	}
+	return screen, nil
+}
+
+func (srv *Server) runSession(sess *Session, ch ssh.Channel) {
+	defer ch.Close()
+	status := uint32(0)
+	defer func() {
+		ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+	}()
+	screen, err := newScreen(sess.tty, sess.term)
+	if err != nil {
+		fmt.Fprintf(ch.Stderr(), "Unable to use terminal %q: %v\r\n", sess.term, err)
+		status = 1
+		return
+	}
+	screen.EnableMouse()
+	screen.EnablePaste()
+	if srv.shared != nil {
+		srv.mu.Lock()
+		color := sessionColors[srv.nextColor%len(sessionColors)]
+		srv.nextColor++
+		srv.mu.Unlock()
+		shared := srv.shared.Attach(screen, sess.User(), color)
+		sess.mu.Lock()
+		sess.shared = shared
+		closed := sess.closed
+		sess.mu.Unlock()
+		if closed {
+			shared.Close()
+			return
+		}
+		go sess.tty.pump(sess.Close)
+		shared.Run()
+		return
+	}
+	sess.app = tview.NewApplication()
+	go sess.tty.pump(sess.Close)
+	var d *tuix.Desktop
+	if srv.newDesktop != nil {
+		d = srv.newDesktop(sess)
+	}
+	if d == nil {
+		screen.Fini()
+		status = 1
+		return
+	}
+	sess.mu.Lock()
+	closed := sess.closed
+	sess.mu.Unlock()
+	if closed {
+		screen.Fini()
+		return
+	}
+	sess.app.SetScreen(tuix.NewPasteScreen(screen, d))
+	d.SetApplication(sess.app)
+	sess.app.SetRoot(d, true)
+	// Close can only stop the application once it runs, see Close.
+	go sess.app.QueueUpdate(func() {
+		sess.mu.Lock()
+		sess.running = !sess.closed
+		closed := sess.closed
+		sess.mu.Unlock()
+		if closed {
+			sess.app.Stop()
+		}
+	})
+	err = sess.app.Run()
+	d.SetApplication(nil) // Stops its timers, the desktop may outlive the session.
+	sess.mu.Lock()
+	sess.running = false
+	sess.mu.Unlock()
+	if err != nil {
+		status = 1
+	}
+}
+
+// Session is an SSH session running a desktop.
+type Session struct {
+	conn    *ssh.ServerConn
+	term    string
+	tty     *channelTty
+	app     *tview.Application
+	shared  *tuix.SharedSession
+	started bool
+	mu      sync.Mutex
+	closed  bool
+	running bool // The application is handling events.
+}
+
+// User gets the name the client authenticated as.
+func (sess *Session) User() string {
+	return sess.conn.User()
+}
+
+// RemoteAddr gets the client's address.
+func (sess *Session) RemoteAddr() net.Addr {
+	return sess.conn.RemoteAddr()
+}
+
+// Permissions gets what the server config's authentication callbacks returned.
+func (sess *Session) Permissions() *ssh.Permissions {
+	return sess.conn.Permissions
+}
+
+// Term gets the client's terminal type, such as xterm-256color.
+func (sess *Session) Term() string {
+	return sess.term
+}
+
+// GetApplication gets the session's application, or nil for a shared desktop.
+func (sess *Session) GetApplication() *tview.Application {
+	return sess.app
+}
+
+// Write writes directly to the client's terminal, such as for Clipboard.SetOSC52.
+func (sess *Session) Write(p []byte) (int, error) {
+	return sess.tty.Write(p)
+}
+
+// Close stops the session's application, which ends the session.
+// It's safe to call from any goroutine, even before the application runs.
+func (sess *Session) Close() {
+	sess.mu.Lock()
+	if sess.closed {
+		sess.mu.Unlock()
+		return
+	}
+	sess.closed = true
+	shared, running := sess.shared, sess.running
+	sess.mu.Unlock()
+	if shared != nil {
+		shared.Close()
+	} else if running {
+		sess.app.Stop()
+	}
+	// Otherwise the application is stopped when it starts, or not run.
+}
@@ -0,0 +1,308 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package sshd_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/sshd"
+	"golang.org/x/crypto/ssh"
+)
+
+// startServer serves on a loopback port, returns its address and a func to close it.
+func startServer(t *testing.T, srv *sshd.Server) (addr string, stop func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(l) }()
+	return l.Addr().String(), func() {
+		srv.Close()
+		if err := <-done; err != sshd.ErrServerClosed {
+			t.Errorf("Serve returned %v", err)
+		}
+	}
+}
+
+func serverConfig(t *testing.T) *ssh.ServerConfig {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+	return config
+}
+
+func dial(addr string) (*ssh.Client, error) {
+	return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            "alice",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+}
+
+// output collects what the server writes to the client's terminal.
+type output struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (out *output) Write(p []byte) (int, error) {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	return out.buf.Write(p)
+}
+
+// waitFor waits until the output contains s.
+func (out *output) waitFor(t *testing.T, s string) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		out.mu.Lock()
+		found := bytes.Contains(out.buf.Bytes(), []byte(s))
+		out.mu.Unlock()
+		if found {
+			return
+		}
+	}
+	t.Fatalf("%q was not written to the terminal", s)
+}
+
+func TestServerSession(t *testing.T) {
+	sessions := make(chan *sshd.Session, 1)
+	srv := sshd.NewServer(serverConfig(t), func(sess *sshd.Session) *tuix.Desktop {
+		sessions <- sess
+		d := tuix.NewDesktop()
+		win := tuix.NewWindow()
+		win.SetTitle("Hello").SetBorder(true).SetRect(2, 2, 20, 6)
+		d.AddWindow(win)
+		return d
+	})
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	client, err := dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	out := &output{}
+	session.Stdout = out
+	stdin, keepOpen := io.Pipe() // Otherwise the client sends EOF, which ends the session.
+	defer keepOpen.Close()
+	session.Stdin = stdin
+	if err := session.RequestPty("xterm", 24, 80, ssh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	sess := <-sessions
+	if sess.User() != "alice" || sess.Term() != "xterm" {
+		t.Errorf("session is for %q with %q", sess.User(), sess.Term())
+	}
+	out.waitFor(t, "Hello")
+
+	sess.Close()
+	if err := session.Wait(); err != nil {
+		t.Errorf("session ended with %v", err)
+	}
+	sess.Close() // After the session ended.
+}
+
+func TestServerStdinEOF(t *testing.T) {
+	srv := sshd.NewServer(serverConfig(t), func(sess *sshd.Session) *tuix.Desktop {
+		return tuix.NewDesktop()
+	})
+	addr, stop := startServer(t, srv)
+	defer stop()
+	client, err := dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if err := session.RequestPty("xterm", 24, 80, ssh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	// Without Stdin, the client sends EOF right away, which closes the session.
+	if err := session.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("session ended with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("session did not end after EOF")
+	}
+}
+
+func TestServerNoPty(t *testing.T) {
+	srv := sshd.NewServer(serverConfig(t), func(sess *sshd.Session) *tuix.Desktop {
+		t.Error("desktop created without a terminal")
+		return nil
+	})
+	addr, stop := startServer(t, srv)
+	defer stop()
+	client, err := dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if err := session.Shell(); err == nil {
+		t.Error("shell started without a pty-req")
+	}
+}
+
+func TestServerMaxConnections(t *testing.T) {
+	srv := sshd.NewServer(serverConfig(t), nil).SetMaxConnections(1)
+	addr, stop := startServer(t, srv)
+	defer stop()
+	client, err := dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client2, err := dial(addr); err == nil {
+		client2.Close()
+		t.Error("connection over the limit was accepted")
+	}
+	client.Close()
+	// The first connection is removed after the server notices it closed.
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		client, err = dial(addr)
+		if err == nil {
+			client.Close()
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("connection after closing the first was refused: %v", err)
+		}
+	}
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	srv := sshd.NewServer(serverConfig(t), nil).SetIdleTimeout(200 * time.Millisecond)
+	addr, stop := startServer(t, srv)
+	defer stop()
+	client, err := dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	done := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("idle client was not disconnected")
+	}
+}
+
+func TestServerUnknownTerm(t *testing.T) {
+	srv := sshd.NewServer(serverConfig(t), func(sess *sshd.Session) *tuix.Desktop {
+		d := tuix.NewDesktop()
+		win := tuix.NewWindow()
+		win.SetTitle("Hello").SetBorder(true).SetRect(2, 2, 20, 6)
+		d.AddWindow(win)
+		return d
+	})
+	addr, stop := startServer(t, srv)
+	defer stop()
+	for _, term := range []string{"-x", "no-such-term", "xterm\x00"} {
+		client, err := dial(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		session, err := client.NewSession()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := &output{}
+		session.Stdout = out
+		stdin, keepOpen := io.Pipe()
+		session.Stdin = stdin
+		if err := session.RequestPty(term, 24, 80, ssh.TerminalModes{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := session.Shell(); err != nil {
+			t.Fatal(err)
+		}
+		out.waitFor(t, "Hello") // As the default terminal type.
+		keepOpen.Close()
+		session.Close()
+		client.Close()
+	}
+}
+
+func TestServerNoDesktop(t *testing.T) {
+	srv := sshd.NewServer(serverConfig(t), nil)
+	addr, stop := startServer(t, srv)
+	defer stop()
+	client, err := dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	stdin, keepOpen := io.Pipe()
+	defer keepOpen.Close()
+	session.Stdin = stdin
+	if err := session.RequestPty("xterm", 24, 80, ssh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+	select {
+	case err := <-done:
+		if exit, ok := err.(*ssh.ExitError); !ok || exit.ExitStatus() != 1 {
+			t.Errorf("session ended with %v, expected exit status 1", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("session without a desktop did not end")
+	}
+}
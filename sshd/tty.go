@@ -0,0 +1,128 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package sshd
+
+import (
+	"io"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/crypto/ssh"
+)
+
+// channelTty is a tcell.Tty for an SSH session channel,
+// sized by the pty-req and window-change requests.
+type channelTty struct {
+	ch        ssh.Channel
+	in        chan []byte // From the pump goroutine, closed when the channel ends.
+	pending   []byte      // Rest of the last input not yet read.
+	drain     chan struct{}
+	drainOnce sync.Once
+	mu        sync.Mutex
+	width     int
+	height    int
+	onResize  func()
+}
+
+var _ tcell.Tty = &channelTty{}
+
+func newChannelTty(ch ssh.Channel, width, height int) *channelTty {
+	tty := &channelTty{
+		ch:     ch,
+		in:     make(chan []byte),
+		drain:  make(chan struct{}),
+		width:  width,
+		height: height,
+	}
+	return tty
+}
+
+// pump reads the channel until it ends, then calls done.
+func (tty *channelTty) pump(done func()) {
+	defer close(tty.in)
+	defer done()
+	for {
+		buf := make([]byte, 256)
+		n, err := tty.ch.Read(buf)
+		if n > 0 {
+			select {
+			case tty.in <- buf[:n]:
+			case <-tty.drain:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (tty *channelTty) Start() error {
+	return nil
+}
+
+func (tty *channelTty) Stop() error {
+	return nil
+}
+
+// Drain wakes up Read, which returns EOF from now on;
+// sessions are not suspended and resumed, so it's only done when finishing.
+func (tty *channelTty) Drain() error {
+	tty.drainOnce.Do(func() {
+		close(tty.drain)
+	})
+	return nil
+}
+
+func (tty *channelTty) NotifyResize(cb func()) {
+	tty.mu.Lock()
+	tty.onResize = cb
+	tty.mu.Unlock()
+}
+
+func (tty *channelTty) WindowSize() (width int, height int, err error) {
+	tty.mu.Lock()
+	defer tty.mu.Unlock()
+	return tty.width, tty.height, nil
+}
+
+// resize is called for window-change requests.
+func (tty *channelTty) resize(width, height int) {
+	tty.mu.Lock()
+	tty.width, tty.height = width, height
+	cb := tty.onResize
+	tty.mu.Unlock()
+	if cb != nil {
+		cb()
+	}
+}
+
+func (tty *channelTty) Read(p []byte) (int, error) {
+	if len(tty.pending) == 0 {
+		select {
+		case data, ok := <-tty.in:
+			if !ok {
+				return 0, io.EOF
+			}
+			tty.pending = data
+		case <-tty.drain:
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, tty.pending)
+	tty.pending = tty.pending[n:]
+	return n, nil
+}
+
+func (tty *channelTty) Write(p []byte) (int, error) {
+	return tty.ch.Write(p)
+}
+
+// Close does nothing, the session closes the channel after sending the exit status.
+func (tty *channelTty) Close() error {
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package terminal
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Keys which are sent as CSI n ~
+var tildeKeys = map[tcell.Key]int{
+	tcell.KeyInsert: 2, tcell.KeyDelete: 3, tcell.KeyPgUp: 5, tcell.KeyPgDn: 6,
+	tcell.KeyF5: 15, tcell.KeyF6: 17, tcell.KeyF7: 18, tcell.KeyF8: 19,
+	tcell.KeyF9: 20, tcell.KeyF10: 21, tcell.KeyF11: 23, tcell.KeyF12: 24,
+}
+
+// Keys which are sent as CSI x, or SS3 x in application cursor mode.
+var cursorKeys = map[tcell.Key]byte{
+	tcell.KeyUp: 'A', tcell.KeyDown: 'B', tcell.KeyRight: 'C', tcell.KeyLeft: 'D',
+	tcell.KeyHome: 'H', tcell.KeyEnd: 'F',
+}
+
+var functionKeys = map[tcell.Key]byte{
+	tcell.KeyF1: 'P', tcell.KeyF2: 'Q', tcell.KeyF3: 'R', tcell.KeyF4: 'S',
+}
+
+// keyBytes gets what xterm sends for the key, or nil.
+func keyBytes(event *tcell.EventKey, appCursor bool) []byte {
+	mods := event.Modifiers()
+	// xterm's modifier parameter.
+	mod := 1
+	if mods&tcell.ModShift != 0 {
+		mod += 1
+	}
+	if mods&tcell.ModAlt != 0 {
+		mod += 2
+	}
+	if mods&tcell.ModCtrl != 0 {
+		mod += 4
+	}
+	key := event.Key()
+	if c, ok := cursorKeys[key]; ok {
+		if mod > 1 {
+			return []byte(fmt.Sprintf("\x1b[1;%d%c", mod, c))
+		}
+		if appCursor {
+			return []byte{0x1b, 'O', c}
+		}
+		return []byte{0x1b, '[', c}
+	}
+	if c, ok := functionKeys[key]; ok {
+		if mod > 1 {
+			return []byte(fmt.Sprintf("\x1b[1;%d%c", mod, c))
+		}
+		return []byte{0x1b, 'O', c}
+	}
+	if n, ok := tildeKeys[key]; ok {
+		if mod > 1 {
+			return []byte(fmt.Sprintf("\x1b[%d;%d~", n, mod))
+		}
+		return []byte(fmt.Sprintf("\x1b[%d~", n))
+	}
+	var p []byte
+	switch key {
+	case tcell.KeyRune:
+		p = []byte(string(event.Rune()))
+	case tcell.KeyEnter:
+		p = []byte{'\r'}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		// tcell reports the Backspace key as KeyBackspace even when the terminal sends DEL,
+		// only Ctrl+H comes with its own rune.
+		p = []byte{0x7f}
+		if event.Rune() == 8 {
+			p[0] = 8
+		}
+	case tcell.KeyBacktab:
+		return []byte("\x1b[Z")
+	default:
+		if key < 0x80 {
+			p = []byte{byte(key)} // Control keys, tab and escape.
+		} else {
+			return nil
+		}
+	}
+	if mods&tcell.ModAlt != 0 {
+		p = append([]byte{0x1b}, p...)
+	}
+	return p
+}
+
+// mouseBytes gets what xterm sends for the mouse action in the mode, or nil.
+func (t *Terminal) mouseBytes(action tview.MouseAction, event *tcell.EventMouse, mode int, sgr bool, col, row int, moved bool) []byte {
+	var btn int
+	release := false
+	switch action {
+	case tview.MouseLeftDown, tview.MouseMiddleDown, tview.MouseRightDown:
+		t.mouseDown = action
+		btn = mouseButtonCode(action)
+	case tview.MouseLeftUp, tview.MouseMiddleUp, tview.MouseRightUp:
+		btn = mouseButtonCode(t.mouseDown)
+		t.mouseDown = 0
+		release = true
+	case tview.MouseScrollUp:
+		btn = 64
+	case tview.MouseScrollDown:
+		btn = 65
+	case tview.MouseMove:
+		if !moved {
+			return nil
+		}
+		if t.mouseDown != 0 && mode >= mouseDrag {
+			btn = mouseButtonCode(t.mouseDown) + 32
+		} else if mode == mouseMotion {
+			btn = 3 + 32
+		} else {
+			return nil
+		}
+	default:
+		return nil // Clicks are already sent as down and up.
+	}
+	mods := event.Modifiers()
+	if mods&tcell.ModShift != 0 {
+		btn += 4
+	}
+	if mods&tcell.ModAlt != 0 {
+		btn += 8
+	}
+	if mods&tcell.ModCtrl != 0 {
+		btn += 16
+	}
+	if sgr {
+		final := 'M'
+		if release {
+			final = 'm'
+		}
+		return []byte(fmt.Sprintf("\x1b[<%d;%d;%d%c", btn, col+1, row+1, final))
+	}
+	if release {
+		btn = 3 | btn&^3
+	}
+	if col > 222 || row > 222 {
+		return nil // Can't be encoded.
+	}
+	return []byte{0x1b, '[', 'M', byte(32 + btn), byte(33 + col), byte(33 + row)}
+}
+
+func mouseButtonCode(action tview.MouseAction) int {
+	switch action {
+	case tview.MouseMiddleDown:
+		return 1
+	case tview.MouseRightDown:
+		return 2
+	}
+	return 0
+}
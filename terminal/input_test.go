@@ -0,0 +1,90 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package terminal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestKeyBytes(t *testing.T) {
+	tests := []struct {
+		event     *tcell.EventKey
+		appCursor bool
+		want      string
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'é', tcell.ModNone), false, "é"},
+		{tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt), false, "\x1bb"},
+		{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), false, "\r"},
+		{tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), false, "\t"},
+		{tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModShift), false, "\x1b[Z"},
+		{tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl), false, "\x03"},
+		{tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), false, "\x1b"},
+		// The Backspace key, as tcell reports it from a terminal sending DEL.
+		{tcell.NewEventKey(tcell.KeyBackspace, 0x7f, tcell.ModNone), false, "\x7f"},
+		{tcell.NewEventKey(tcell.KeyBackspace2, 0x7f, tcell.ModNone), false, "\x7f"},
+		{tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModNone), false, "\x7f"},
+		// Ctrl+H, as tcell reports it from a terminal sending BS.
+		{tcell.NewEventKey(tcell.KeyRune, 8, tcell.ModNone), false, "\x08"},
+		{tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), false, "\x1b[A"},
+		{tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), true, "\x1bOA"},
+		{tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModCtrl), true, "\x1b[1;5D"},
+		{tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModAlt), false, "\x1b[1;3C"},
+		{tcell.NewEventKey(tcell.KeyHome, 0, tcell.ModShift), false, "\x1b[1;2H"},
+		{tcell.NewEventKey(tcell.KeyF1, 0, tcell.ModNone), false, "\x1bOP"},
+		{tcell.NewEventKey(tcell.KeyF4, 0, tcell.ModShift), false, "\x1b[1;2S"},
+		{tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModNone), false, "\x1b[15~"},
+		{tcell.NewEventKey(tcell.KeyDelete, 0, tcell.ModCtrl), false, "\x1b[3;5~"},
+		{tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone), false, "\x1b[6~"},
+		{tcell.NewEventKey(tcell.KeyF64, 0, tcell.ModNone), false, ""},
+	}
+	for _, tt := range tests {
+		if got := string(keyBytes(tt.event, tt.appCursor)); got != tt.want {
+			t.Errorf("%s (app cursor %v) sends %q, expected %q", tt.event.Name(), tt.appCursor, got, tt.want)
+		}
+	}
+}
+
+func TestMouseBytes(t *testing.T) {
+	term := NewTerminal()
+	at := func(mod tcell.ModMask) *tcell.EventMouse {
+		return tcell.NewEventMouse(0, 0, tcell.ButtonNone, mod)
+	}
+	tests := []struct {
+		action tview.MouseAction
+		mod    tcell.ModMask
+		mode   int
+		sgr    bool
+		col    int
+		row    int
+		moved  bool
+		want   string
+	}{
+		{tview.MouseLeftDown, 0, mousePress, false, 0, 0, true, "\x1b[M !!"},
+		{tview.MouseMove, 0, mousePress, false, 1, 0, true, ""}, // No motion in press mode.
+		{tview.MouseLeftUp, 0, mousePress, false, 1, 0, false, "\x1b[M#\"!"},
+		{tview.MouseRightDown, tcell.ModCtrl, mousePress, true, 4, 9, true, "\x1b[<18;5;10M"},
+		{tview.MouseMove, 0, mouseDrag, true, 5, 9, true, "\x1b[<34;6;10M"},
+		{tview.MouseMove, 0, mouseDrag, true, 5, 9, false, ""}, // Same cell.
+		{tview.MouseRightUp, 0, mouseDrag, true, 5, 9, false, "\x1b[<2;6;10m"},
+		{tview.MouseMove, 0, mouseDrag, true, 6, 9, true, ""}, // No button down.
+		{tview.MouseMove, 0, mouseMotion, true, 7, 9, true, "\x1b[<35;8;10M"},
+		{tview.MouseScrollUp, tcell.ModShift, mousePress, false, 0, 0, true, "\x1b[Md!!"},
+		{tview.MouseScrollDown, 0, mousePress, true, 0, 0, true, "\x1b[<65;1;1M"},
+		{tview.MouseLeftClick, 0, mousePress, true, 0, 0, true, ""},
+		{tview.MouseLeftDown, 0, mousePress, false, 300, 0, true, ""}, // Too far for the X10 encoding.
+		{tview.MouseLeftDown, 0, mousePress, true, 300, 0, true, "\x1b[<0;301;1M"},
+	}
+	for i, tt := range tests {
+		got := string(term.mouseBytes(tt.action, at(tt.mod), tt.mode, tt.sgr, tt.col, tt.row, tt.moved))
+		if got != tt.want {
+			t.Errorf("%d: sends %q, expected %q", i, got, tt.want)
+		}
+	}
+}
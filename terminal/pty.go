@@ -0,0 +1,64 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !windows
+// +build !windows
+
+package terminal
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/creack/pty"
+)
+
+// Start runs the command in a pseudo terminal the size of the terminal.
+// TERM is set to xterm-256color unless the command's environment sets it.
+func (t *Terminal) Start(cmd *exec.Cmd) error {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	hasTerm := false
+	for _, kv := range cmd.Env {
+		if len(kv) >= 5 && kv[:5] == "TERM=" {
+			hasTerm = true
+		}
+	}
+	if !hasTerm {
+		cmd.Env = append(cmd.Env, "TERM=xterm-256color")
+	}
+	t.mu.Lock()
+	size := &pty.Winsize{Cols: uint16(t.vt.width), Rows: uint16(t.vt.height)}
+	t.mu.Unlock()
+	f, err := pty.StartWithSize(cmd, size)
+	if err != nil {
+		return err
+	}
+	t.input = f
+	t.closer = f
+	t.resize = func(width, height int) {
+		pty.Setsize(f, &pty.Winsize{Cols: uint16(width), Rows: uint16(height)})
+	}
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				t.Write(buf[:n])
+			}
+			if err != nil {
+				break
+			}
+		}
+		f.Close()
+		err := cmd.Wait()
+		if t.done != nil {
+			t.done(err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !windows
+// +build !windows
+
+package terminal
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// screenText gets the text of the terminal's visible lines.
+func (t *Terminal) screenText() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var lines []string
+	for _, line := range t.vt.lines {
+		lines = append(lines, lineText(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestStartShell(t *testing.T) {
+	if _, err := exec.LookPath("/bin/sh"); err != nil {
+		t.Skip(err)
+	}
+	term := NewTerminal()
+	term.SetRect(0, 0, 40, 10)
+	done := make(chan error, 1)
+	term.SetDoneFunc(func(err error) { done <- err })
+	cmd := exec.Command("/bin/sh")
+	cmd.Env = []string{"PS1=$ ", "PATH=/usr/bin:/bin"}
+	if err := term.Start(cmd); err != nil {
+		t.Fatal(err)
+	}
+	defer term.Close()
+
+	waitFor := func(s string) {
+		t.Helper()
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+			if strings.Contains(term.screenText(), s) {
+				return
+			}
+		}
+		t.Fatalf("%q is not on the screen:\n%s", s, term.screenText())
+	}
+	typeKeys := func(text string) {
+		handler := term.InputHandler()
+		for _, r := range text {
+			handler(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), func(tview.Primitive) {})
+		}
+		handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+	typeKeys("echo $TERM $((6*7)); stty size")
+	waitFor("xterm-256color 42")
+	waitFor("10 40")
+
+	term.SetRect(0, 0, 30, 8) // Resizes the pty.
+	typeKeys("stty size")
+	waitFor("8 30")
+
+	typeKeys("exit 3")
+	select {
+	case err := <-done:
+		if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 3 {
+			t.Errorf("shell exited with %v, expected status 3", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("shell did not exit")
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package terminal
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// Start is not supported on Windows, which has no pseudo terminals;
+// use Write and SetInput instead.
+func (t *Terminal) Start(cmd *exec.Cmd) error {
+	return errors.New("terminal: pseudo terminals are not supported on windows")
+}
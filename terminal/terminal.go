@@ -0,0 +1,271 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package terminal is a terminal emulator primitive,
+// which runs a program in a pseudo terminal and shows it,
+// such as in a tuix.Window: win.SetClient(term, true)
+package terminal
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+// Terminal is a primitive which emulates an xterm compatible terminal.
+// Start runs a program in it; or use Write and SetInput to connect it to anything else.
+type Terminal struct {
+	*tview.Box
+	mu         sync.Mutex
+	vt         *vt
+	input      io.Writer
+	closer     io.Closer
+	resize     func(width, height int) // Resizes the pty.
+	changed    func()
+	done       func(err error)
+	viewOffset int               // Lines scrolled back into the scrollback.
+	mouseDown  tview.MouseAction // Button held for the program, 0 if none.
+	lastMouseX int               // Last mouse position sent, to only send motion on change.
+	lastMouseY int
+}
+
+// NewTerminal creates a new terminal, use Start to run a program in it.
+func NewTerminal() *Terminal {
+	t := &Terminal{
+		Box: tview.NewBox(),
+		vt:  newVT(80, 24),
+	}
+	t.SetBackgroundColor(tcell.ColorDefault)
+	return t
+}
+
+// SetChangedFunc sets a function called when the program changes the terminal.
+// It is called from another goroutine, so it usually calls Application.QueueUpdateDraw
+// or Application.Draw.
+func (t *Terminal) SetChangedFunc(handler func()) *Terminal {
+	t.changed = handler
+	return t
+}
+
+// SetDoneFunc sets a function called when the program exits,
+// with the error from exec.Cmd.Wait.
+// It is called from another goroutine, like the changed func.
+func (t *Terminal) SetDoneFunc(handler func(err error)) *Terminal {
+	t.done = handler
+	return t
+}
+
+// SetScrollback sets how many lines scrolled off the top are kept, the default is 1000.
+func (t *Terminal) SetScrollback(lines int) *Terminal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.vt.maxScrollback = lines
+	t.vt.pushScrollback(nil) // Trim it.
+	if lines <= 0 {
+		t.vt.scrollback = nil
+	}
+	return t
+}
+
+// SetInput sets where keys and mouse events for the program are written.
+// Start sets it to the pseudo terminal.
+func (t *Terminal) SetInput(w io.Writer) *Terminal {
+	t.input = w
+	return t
+}
+
+// GetTitle gets the title the program set, or an empty string.
+func (t *Terminal) GetTitle() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.vt.title
+}
+
+// Write parses output from the program, it's safe to call from any goroutine.
+// Replies to the program, such as the cursor position, are written to the input.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	t.vt.write(p)
+	reply := t.vt.reply
+	t.vt.reply = nil
+	t.mu.Unlock()
+	if len(reply) > 0 && t.input != nil {
+		t.input.Write(reply)
+	}
+	if t.changed != nil {
+		t.changed()
+	}
+	return len(p), nil
+}
+
+// Close closes the pseudo terminal, which hangs up the program.
+func (t *Terminal) Close() error {
+	if t.closer != nil {
+		return t.closer.Close()
+	}
+	return nil
+}
+
+// Paste sends text to the program as if it was typed,
+// marked as a paste if the program asked for bracketed paste.
+func (t *Terminal) Paste(text string) {
+	t.mu.Lock()
+	bracketed := t.vt.bracketedPaste
+	t.mu.Unlock()
+	text = strings.Replace(strings.Replace(text, "\r\n", "\r", -1), "\n", "\r", -1) // Like a terminal.
+	if bracketed {
+		text = "\x1b[200~" + text + "\x1b[201~"
+	}
+	t.send([]byte(text))
+}
+
+var _ tuix.DropTarget = &Terminal{}
+
+// DragOver accepts dropping text and files.
+func (t *Terminal) DragOver(drag *tuix.Drag, x, y int) bool {
+	switch drag.Payload.(type) {
+	case tuix.DragText, tuix.DragFile:
+		return true
+	}
+	return false
+}
+
+// DragLeave does nothing.
+func (t *Terminal) DragLeave(drag *tuix.Drag) {
+}
+
+// Drop pastes the text, or the file's path quoted for a shell.
+func (t *Terminal) Drop(drag *tuix.Drag, x, y int) {
+	switch payload := drag.Payload.(type) {
+	case tuix.DragText:
+		t.Paste(string(payload))
+	case tuix.DragFile:
+		t.Paste("'" + strings.Replace(string(payload), "'", `'\''`, -1) + "' ")
+	}
+}
+
+func (t *Terminal) send(p []byte) {
+	if t.input != nil {
+		t.input.Write(p)
+	}
+}
+
+func (t *Terminal) SetRect(x, y, width, height int) {
+	t.Box.SetRect(x, y, width, height)
+	_, _, w, h := t.GetInnerRect()
+	t.mu.Lock()
+	oldW, oldH := t.vt.width, t.vt.height
+	t.vt.resize(w, h)
+	w, h = t.vt.width, t.vt.height
+	t.mu.Unlock()
+	if t.resize != nil && (w != oldW || h != oldH) {
+		t.resize(w, h)
+	}
+}
+
+// viewLine gets the line to show on the row, considering the scrollback.
+func (t *Terminal) viewLine(row int) []cell {
+	v := t.vt
+	row -= t.viewOffset
+	if row >= 0 {
+		return v.lines[row]
+	}
+	if i := len(v.scrollback) + row; i >= 0 {
+		return v.scrollback[i]
+	}
+	return nil
+}
+
+func (t *Terminal) Draw(screen tcell.Screen) {
+	t.Box.DrawForSubclass(screen, t)
+	x, y, w, h := t.GetInnerRect()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	v := t.vt
+	t.viewOffset = clamp(t.viewOffset, 0, len(v.scrollback))
+	for row := 0; row < h && row < v.height; row++ {
+		line := t.viewLine(row)
+		for col := 0; col < w && col < len(line); col++ {
+			if c := line[col]; c.ch != 0 {
+				screen.SetContent(x+col, y+row, c.ch, nil, c.style)
+			}
+		}
+	}
+	if t.HasFocus() && !v.cursorHidden && t.viewOffset == 0 && v.cx < w && v.cy < h {
+		screen.ShowCursor(x+v.cx, y+v.cy)
+	}
+}
+
+// scrollView scrolls back into the scrollback by lines, negative to go forward.
+func (t *Terminal) scrollView(lines int) {
+	t.mu.Lock()
+	t.viewOffset = clamp(t.viewOffset+lines, 0, len(t.vt.scrollback))
+	t.mu.Unlock()
+}
+
+func (t *Terminal) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		_, _, _, h := t.GetInnerRect()
+		if event.Modifiers()&tcell.ModShift != 0 {
+			switch event.Key() {
+			case tcell.KeyPgUp:
+				t.scrollView(h / 2)
+				return
+			case tcell.KeyPgDn:
+				t.scrollView(-h / 2)
+				return
+			}
+		}
+		t.mu.Lock()
+		t.viewOffset = 0
+		appCursor := t.vt.appCursor
+		t.mu.Unlock()
+		if p := keyBytes(event, appCursor); p != nil {
+			t.send(p)
+		}
+	})
+}
+
+func (t *Terminal) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return t.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		atX, atY := event.Position()
+		if !t.InRect(atX, atY) && t.mouseDown == 0 {
+			return false, nil
+		}
+		if action == tview.MouseLeftDown {
+			setFocus(t)
+		}
+		x, y, w, h := t.GetInnerRect()
+		col, row := clamp(atX-x, 0, w-1), clamp(atY-y, 0, h-1)
+		t.mu.Lock()
+		mode, sgr := t.vt.mouseMode, t.vt.mouseSGR
+		t.mu.Unlock()
+		if mode == mouseOff {
+			switch action {
+			case tview.MouseScrollUp:
+				t.scrollView(3)
+			case tview.MouseScrollDown:
+				t.scrollView(-3)
+			default:
+				return action == tview.MouseLeftDown, nil
+			}
+			return true, nil
+		}
+		moved := col != t.lastMouseX || row != t.lastMouseY
+		t.lastMouseX, t.lastMouseY = col, row
+		if p := t.mouseBytes(action, event, mode, sgr, col, row, moved); p != nil {
+			t.send(p)
+		}
+		if t.mouseDown != 0 {
+			return true, t // Get the release, even outside.
+		}
+		return true, nil
+	})
+}
@@ -0,0 +1,671 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package terminal
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// cell is one character cell of the terminal.
+type cell struct {
+	ch    rune // 0 for the right half of a wide rune.
+	style tcell.Style
+}
+
+// Limits of escape sequences, like xterm; the rest of a longer sequence is ignored.
+const (
+	maxParams = 30
+	maxParam  = 65535
+	maxOSC    = 1024 // Only the title is used.
+)
+
+// Parser states.
+const (
+	stateGround = iota
+	stateEscape
+	stateCharset // After ESC ( and friends.
+	stateCSI
+	stateOSC
+	stateOSCEscape // ESC in an OSC, expecting the \ of ST.
+)
+
+// Mouse tracking modes an application can request.
+const (
+	mouseOff    = 0
+	mousePress  = 1000 // Presses and releases.
+	mouseDrag   = 1002 // Also motion with a button down.
+	mouseMotion = 1003 // Also all motion.
+)
+
+// vt is an xterm compatible screen, it parses the output of a program.
+// It is not safe for concurrent use.
+type vt struct {
+	width, height  int
+	lines          [][]cell // The visible screen.
+	mainLines      [][]cell // The main screen while the alternate screen is used.
+	alt            bool
+	scrollback     [][]cell // Oldest first.
+	maxScrollback  int
+	cx, cy         int
+	wrapNext       bool // The cursor is past the last column, wrap on the next rune.
+	style          tcell.Style
+	savedX, savedY int
+	savedStyle     tcell.Style
+	top, bottom    int // Scroll region, inclusive.
+	cursorHidden   bool
+	noAutowrap     bool
+	insertMode     bool
+	appCursor      bool // Application cursor keys.
+	bracketedPaste bool
+	mouseMode      int
+	mouseSGR       bool
+	lineDrawing    bool // G0 is the DEC special graphics set.
+	title          string
+	reply          []byte // Responses for the program, such as the cursor position.
+
+	state   int
+	params  []int
+	param   int
+	inParam bool
+	private byte // Such as the ? of CSI ? 25 h.
+	charset byte // Which set ESC ( is changing.
+	osc     []byte
+	utf8buf []byte
+}
+
+func newVT(width, height int) *vt {
+	v := &vt{maxScrollback: 1000}
+	v.resize(width, height)
+	return v
+}
+
+func blankLine(width int, style tcell.Style) []cell {
+	line := make([]cell, width)
+	for i := range line {
+		line[i] = cell{' ', style}
+	}
+	return line
+}
+
+// blank is the style of erased cells, which keep the current background.
+func (v *vt) blank() tcell.Style {
+	_, bg, _ := v.style.Decompose()
+	return tcell.StyleDefault.Background(bg)
+}
+
+func resizeLines(lines [][]cell, width, height int) [][]cell {
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+	for i, line := range lines {
+		if len(line) > width {
+			lines[i] = line[:width]
+		} else if len(line) < width {
+			lines[i] = append(line, blankLine(width-len(line), tcell.StyleDefault)...)
+		}
+	}
+	for len(lines) < height {
+		lines = append(lines, blankLine(width, tcell.StyleDefault))
+	}
+	return lines
+}
+
+func (v *vt) resize(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	if width == v.width && height == v.height {
+		return
+	}
+	if !v.alt && len(v.lines) > height && v.cy >= height {
+		// Keep the cursor line on the screen, the rest goes to the scrollback.
+		n := v.cy - height + 1
+		v.pushScrollback(v.lines[:n])
+		v.lines = v.lines[n:]
+		v.cy -= n
+	}
+	v.lines = resizeLines(v.lines, width, height)
+	if v.mainLines != nil {
+		v.mainLines = resizeLines(v.mainLines, width, height)
+	}
+	v.width, v.height = width, height
+	v.top, v.bottom = 0, height-1
+	v.cx, v.cy = clamp(v.cx, 0, width-1), clamp(v.cy, 0, height-1)
+	v.wrapNext = false
+}
+
+func clamp(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
+
+func (v *vt) pushScrollback(lines [][]cell) {
+	if v.maxScrollback <= 0 {
+		return
+	}
+	for _, line := range lines {
+		v.scrollback = append(v.scrollback, append([]cell(nil), line...))
+	}
+	if over := len(v.scrollback) - v.maxScrollback; over > 0 {
+		copy(v.scrollback, v.scrollback[over:])
+		v.scrollback = v.scrollback[:v.maxScrollback]
+	}
+}
+
+// write parses output from the program.
+func (v *vt) write(p []byte) {
+	for _, b := range p {
+		v.writeByte(b)
+	}
+}
+
+func (v *vt) writeByte(b byte) {
+	switch v.state {
+	case stateOSC:
+		switch b {
+		case 7:
+			v.endOSC()
+		case 0x1b:
+			v.state = stateOSCEscape
+		default:
+			if len(v.osc) < maxOSC {
+				v.osc = append(v.osc, b)
+			}
+		}
+		return
+	case stateOSCEscape:
+		v.endOSC()
+		if b != '\\' {
+			v.writeByte(b)
+		}
+		return
+	}
+
+	if b < 0x20 || b == 0x7f {
+		v.utf8buf = v.utf8buf[:0]
+		v.control(b)
+		return
+	}
+
+	switch v.state {
+	case stateGround:
+		if b < 0x80 {
+			v.put(rune(b))
+			return
+		}
+		v.utf8buf = append(v.utf8buf, b)
+		if utf8.FullRune(v.utf8buf) {
+			r, _ := utf8.DecodeRune(v.utf8buf)
+			v.utf8buf = v.utf8buf[:0]
+			v.put(r)
+		}
+	case stateEscape:
+		v.escape(b)
+	case stateCharset:
+		if v.charset == '(' {
+			v.lineDrawing = b == '0'
+		}
+		v.state = stateGround
+	case stateCSI:
+		switch {
+		case b >= '0' && b <= '9':
+			if v.param = v.param*10 + int(b-'0'); v.param > maxParam {
+				v.param = maxParam
+			}
+			v.inParam = true
+		case b == ';' || b == ':':
+			if len(v.params) < maxParams {
+				v.params = append(v.params, v.param)
+			}
+			v.param, v.inParam = 0, false
+		case b == '?' || b == '>' || b == '=' || b == '<':
+			v.private = b
+		case b >= 0x40 && b <= 0x7e:
+			if (v.inParam || len(v.params) > 0) && len(v.params) < maxParams {
+				v.params = append(v.params, v.param)
+			}
+			v.state = stateGround
+			v.csi(b)
+		}
+	}
+}
+
+func (v *vt) control(b byte) {
+	switch b {
+	case 7: // BEL
+	case 8: // BS
+		if v.cx > 0 {
+			v.cx--
+		}
+		v.wrapNext = false
+	case 9: // HT
+		v.cx = clamp((v.cx/8+1)*8, 0, v.width-1)
+		v.wrapNext = false
+	case 10, 11, 12: // LF, VT, FF
+		v.lineFeed()
+	case 13: // CR
+		v.cx = 0
+		v.wrapNext = false
+	case 0x18, 0x1a: // CAN, SUB
+		v.state = stateGround
+	case 0x1b:
+		v.state = stateEscape
+		v.params, v.param, v.inParam, v.private = v.params[:0], 0, false, 0
+	}
+}
+
+func (v *vt) escape(b byte) {
+	v.state = stateGround
+	switch b {
+	case '[':
+		v.state = stateCSI
+	case ']':
+		v.state = stateOSC
+		v.osc = v.osc[:0]
+	case '(', ')', '*', '+':
+		v.charset = b
+		v.state = stateCharset
+	case '7':
+		v.saveCursor()
+	case '8':
+		v.restoreCursor()
+	case 'D': // IND
+		v.lineFeed()
+	case 'E': // NEL
+		v.cx = 0
+		v.lineFeed()
+	case 'M': // RI
+		if v.cy == v.top {
+			v.scrollDown(1)
+		} else if v.cy > 0 {
+			v.cy--
+		}
+		v.wrapNext = false
+	case 'c': // RIS
+		*v = vt{width: v.width, height: v.height, lines: v.lines, scrollback: v.scrollback, maxScrollback: v.maxScrollback}
+		v.bottom = v.height - 1
+		v.eraseDisplay(2)
+	}
+}
+
+func (v *vt) endOSC() {
+	v.state = stateGround
+	// Only the title is supported: 0 sets the icon name and title, 2 the title.
+	s := string(v.osc)
+	if len(s) >= 2 && (s[0] == '0' || s[0] == '2') && s[1] == ';' {
+		v.title = s[2:]
+	}
+}
+
+func (v *vt) saveCursor() {
+	v.savedX, v.savedY, v.savedStyle = v.cx, v.cy, v.style
+}
+
+func (v *vt) restoreCursor() {
+	v.cx, v.cy, v.style = clamp(v.savedX, 0, v.width-1), clamp(v.savedY, 0, v.height-1), v.savedStyle
+	v.wrapNext = false
+}
+
+// decGraphics maps the DEC special graphics set to runes.
+var decGraphics = map[rune]rune{
+	'`': '◆', 'a': '▒', 'f': '°', 'g': '±', 'j': '┘', 'k': '┐', 'l': '┌', 'm': '└',
+	'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽', 't': '├', 'u': '┤',
+	'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥', '{': 'π', '|': '≠', '}': '£', '~': '·',
+}
+
+// put prints a rune at the cursor.
+func (v *vt) put(r rune) {
+	if v.lineDrawing {
+		if g, ok := decGraphics[r]; ok {
+			r = g
+		}
+	}
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		return // Combining runes are not supported.
+	}
+	if w > v.width {
+		r, w = ' ', 1 // A wide rune doesn't fit in a 1 column terminal.
+	}
+	if v.wrapNext || v.cx+w > v.width {
+		if v.noAutowrap {
+			v.cx = v.width - w
+		} else {
+			v.cx = 0
+			v.lineFeed()
+		}
+		v.wrapNext = false
+	}
+	line := v.lines[v.cy]
+	if v.insertMode {
+		copy(line[v.cx+w:], line[v.cx:])
+	}
+	line[v.cx] = cell{r, v.style}
+	if w == 2 && v.cx+1 < v.width {
+		line[v.cx+1] = cell{0, v.style}
+	}
+	v.cx += w
+	if v.cx >= v.width {
+		v.cx = v.width - 1
+		v.wrapNext = true
+	}
+}
+
+func (v *vt) lineFeed() {
+	v.wrapNext = false
+	if v.cy == v.bottom {
+		v.scrollUp(1)
+	} else if v.cy < v.height-1 {
+		v.cy++
+	}
+}
+
+// scrollUp scrolls the scroll region up, lines scrolled off the top
+// of the main screen go to the scrollback.
+func (v *vt) scrollUp(n int) {
+	n = clamp(n, 0, v.bottom-v.top+1)
+	if v.top == 0 && !v.alt {
+		v.pushScrollback(v.lines[:n])
+	}
+	region := v.lines[v.top : v.bottom+1]
+	copy(region, region[n:])
+	for i := len(region) - n; i < len(region); i++ {
+		region[i] = blankLine(v.width, v.blank())
+	}
+}
+
+func (v *vt) scrollDown(n int) {
+	n = clamp(n, 0, v.bottom-v.top+1)
+	region := v.lines[v.top : v.bottom+1]
+	copy(region[n:], region)
+	for i := 0; i < n; i++ {
+		region[i] = blankLine(v.width, v.blank())
+	}
+}
+
+func (v *vt) eraseCells(line []cell, from, to int) {
+	from, to = clamp(from, 0, len(line)), clamp(to, 0, len(line))
+	for i := from; i < to; i++ {
+		line[i] = cell{' ', v.blank()}
+	}
+}
+
+func (v *vt) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		v.eraseCells(v.lines[v.cy], v.cx, v.width)
+		for j := v.cy + 1; j < v.height; j++ {
+			v.eraseCells(v.lines[j], 0, v.width)
+		}
+	case 1:
+		v.eraseCells(v.lines[v.cy], 0, v.cx+1)
+		for j := 0; j < v.cy; j++ {
+			v.eraseCells(v.lines[j], 0, v.width)
+		}
+	case 2:
+		for j := 0; j < v.height; j++ {
+			v.eraseCells(v.lines[j], 0, v.width)
+		}
+	case 3:
+		v.scrollback = nil
+	}
+}
+
+// param gets the nth CSI parameter, or def if it's missing or 0.
+func (v *vt) csiParam(n, def int) int {
+	if n < len(v.params) && v.params[n] != 0 {
+		return v.params[n]
+	}
+	return def
+}
+
+func (v *vt) csi(final byte) {
+	if v.private == '?' {
+		switch final {
+		case 'h':
+			v.setPrivateModes(true)
+		case 'l':
+			v.setPrivateModes(false)
+		}
+		return
+	}
+	if v.private != 0 {
+		if v.private == '>' && final == 'c' { // Secondary device attributes.
+			v.reply = append(v.reply, "\x1b[>0;0;0c"...)
+		}
+		return
+	}
+	n := v.csiParam(0, 1)
+	switch final {
+	case 'A':
+		v.cy = clamp(v.cy-n, 0, v.height-1)
+	case 'B', 'e':
+		v.cy = clamp(v.cy+n, 0, v.height-1)
+	case 'C', 'a':
+		v.cx = clamp(v.cx+n, 0, v.width-1)
+	case 'D':
+		v.cx = clamp(v.cx-n, 0, v.width-1)
+	case 'E':
+		v.cx, v.cy = 0, clamp(v.cy+n, 0, v.height-1)
+	case 'F':
+		v.cx, v.cy = 0, clamp(v.cy-n, 0, v.height-1)
+	case 'G', '`':
+		v.cx = clamp(n-1, 0, v.width-1)
+	case 'd':
+		v.cy = clamp(n-1, 0, v.height-1)
+	case 'H', 'f':
+		v.cy = clamp(v.csiParam(0, 1)-1, 0, v.height-1)
+		v.cx = clamp(v.csiParam(1, 1)-1, 0, v.width-1)
+	case 'J':
+		v.eraseDisplay(v.csiParam(0, 0))
+	case 'K':
+		switch v.csiParam(0, 0) {
+		case 0:
+			v.eraseCells(v.lines[v.cy], v.cx, v.width)
+		case 1:
+			v.eraseCells(v.lines[v.cy], 0, v.cx+1)
+		case 2:
+			v.eraseCells(v.lines[v.cy], 0, v.width)
+		}
+	case 'L', 'M':
+		if v.cy >= v.top && v.cy <= v.bottom {
+			top := v.top
+			v.top = v.cy
+			if final == 'L' {
+				v.scrollDown(n)
+			} else {
+				alt := v.alt
+				v.alt = true // Deleted lines don't go to the scrollback.
+				v.scrollUp(n)
+				v.alt = alt
+			}
+			v.top = top
+			v.cx = 0
+		}
+	case 'P':
+		line := v.lines[v.cy]
+		n = clamp(n, 0, v.width-v.cx)
+		copy(line[v.cx:], line[v.cx+n:])
+		v.eraseCells(line, v.width-n, v.width)
+	case '@':
+		line := v.lines[v.cy]
+		n = clamp(n, 0, v.width-v.cx)
+		copy(line[v.cx+n:], line[v.cx:])
+		v.eraseCells(line, v.cx, v.cx+n)
+	case 'X':
+		v.eraseCells(v.lines[v.cy], v.cx, v.cx+n)
+	case 'S':
+		v.scrollUp(n)
+	case 'T':
+		v.scrollDown(n)
+	case 'r':
+		top, bottom := v.csiParam(0, 1)-1, v.csiParam(1, v.height)-1
+		if top < bottom && bottom < v.height {
+			v.top, v.bottom = top, bottom
+			v.cx, v.cy = 0, 0
+		}
+	case 'm':
+		v.sgr()
+	case 'h', 'l':
+		if v.csiParam(0, 0) == 4 {
+			v.insertMode = final == 'h'
+		}
+	case 's':
+		v.saveCursor()
+	case 'u':
+		v.restoreCursor()
+	case 'n':
+		switch v.csiParam(0, 0) {
+		case 5:
+			v.reply = append(v.reply, "\x1b[0n"...)
+		case 6:
+			v.reply = append(v.reply, fmt.Sprintf("\x1b[%d;%dR", v.cy+1, v.cx+1)...)
+		}
+	case 'c':
+		v.reply = append(v.reply, "\x1b[?62;22c"...)
+	}
+	v.wrapNext = false
+}
+
+func (v *vt) setPrivateModes(on bool) {
+	for _, mode := range v.params {
+		switch mode {
+		case 1:
+			v.appCursor = on
+		case 7:
+			v.noAutowrap = !on
+		case 25:
+			v.cursorHidden = !on
+		case mousePress, mouseDrag, mouseMotion:
+			if on {
+				v.mouseMode = mode
+			} else {
+				v.mouseMode = mouseOff
+			}
+		case 1006:
+			v.mouseSGR = on
+		case 47, 1047, 1049:
+			v.setAltScreen(on, mode == 1049)
+		case 2004:
+			v.bracketedPaste = on
+		}
+	}
+}
+
+func (v *vt) setAltScreen(on, saveCursor bool) {
+	if on == v.alt {
+		return
+	}
+	if on {
+		if saveCursor {
+			v.saveCursor()
+		}
+		v.mainLines = v.lines
+		v.lines = resizeLines(nil, v.width, v.height)
+	} else {
+		v.lines = v.mainLines
+		v.mainLines = nil
+		if saveCursor {
+			v.restoreCursor()
+		}
+	}
+	v.alt = on
+	v.top, v.bottom = 0, v.height-1
+}
+
+// sgr sets the style from the parameters of CSI m.
+func (v *vt) sgr() {
+	if len(v.params) == 0 {
+		v.style = tcell.StyleDefault
+		return
+	}
+	for i := 0; i < len(v.params); i++ {
+		p := v.params[i]
+		switch {
+		case p == 0:
+			v.style = tcell.StyleDefault
+		case p == 1:
+			v.style = v.style.Bold(true)
+		case p == 2:
+			v.style = v.style.Dim(true)
+		case p == 3:
+			v.style = v.style.Italic(true)
+		case p == 4:
+			v.style = v.style.Underline(true)
+		case p == 5 || p == 6:
+			v.style = v.style.Blink(true)
+		case p == 7:
+			v.style = v.style.Reverse(true)
+		case p == 9:
+			v.style = v.style.StrikeThrough(true)
+		case p == 22:
+			v.style = v.style.Bold(false).Dim(false)
+		case p == 23:
+			v.style = v.style.Italic(false)
+		case p == 24:
+			v.style = v.style.Underline(false)
+		case p == 25:
+			v.style = v.style.Blink(false)
+		case p == 27:
+			v.style = v.style.Reverse(false)
+		case p == 29:
+			v.style = v.style.StrikeThrough(false)
+		case p >= 30 && p <= 37:
+			v.style = v.style.Foreground(tcell.PaletteColor(p - 30))
+		case p == 38 || p == 48:
+			var color tcell.Color
+			color, i = v.extendedColor(i)
+			if p == 38 {
+				v.style = v.style.Foreground(color)
+			} else {
+				v.style = v.style.Background(color)
+			}
+		case p == 39:
+			v.style = v.style.Foreground(tcell.ColorDefault)
+		case p >= 40 && p <= 47:
+			v.style = v.style.Background(tcell.PaletteColor(p - 40))
+		case p == 49:
+			v.style = v.style.Background(tcell.ColorDefault)
+		case p >= 90 && p <= 97:
+			v.style = v.style.Foreground(tcell.PaletteColor(p - 90 + 8))
+		case p >= 100 && p <= 107:
+			v.style = v.style.Background(tcell.PaletteColor(p - 100 + 8))
+		}
+	}
+}
+
+// extendedColor parses 5;n or 2;r;g;b after the 38 or 48 at params[i],
+// returns the index of the last parameter used.
+func (v *vt) extendedColor(i int) (tcell.Color, int) {
+	if i+1 >= len(v.params) {
+		return tcell.ColorDefault, i
+	}
+	switch v.params[i+1] {
+	case 5:
+		if i+2 < len(v.params) {
+			return tcell.PaletteColor(clamp(v.params[i+2], 0, 255)), i + 2
+		}
+	case 2:
+		if i+4 < len(v.params) {
+			r, g, b := v.params[i+2], v.params[i+3], v.params[i+4]
+			return tcell.NewRGBColor(int32(clamp(r, 0, 255)), int32(clamp(g, 0, 255)), int32(clamp(b, 0, 255))), i + 4
+		}
+	}
+	return tcell.ColorDefault, len(v.params)
+}
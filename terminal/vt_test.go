@@ -0,0 +1,234 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package terminal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestVTTitle(t *testing.T) {
+	v := newVT(20, 5)
+	v.write([]byte("\x1b]2;hello\x07"))
+	if v.title != "hello" {
+		t.Errorf("title is %q", v.title)
+	}
+	v.write([]byte("\x1b]0;world\x1b\\x"))
+	if v.title != "world" {
+		t.Errorf("title is %q", v.title)
+	}
+	if v.lines[0][0].ch != 'x' {
+		t.Errorf("text after the title is %q", v.lines[0][0].ch)
+	}
+}
+
+func TestVTLongOSC(t *testing.T) {
+	v := newVT(20, 5)
+	v.write([]byte("\x1b]2;"))
+	long := []byte(strings.Repeat("a", 1<<16))
+	for i := 0; i < 16; i++ {
+		v.write(long)
+	}
+	if len(v.osc) > maxOSC || cap(v.osc) > 2*maxOSC {
+		t.Errorf("OSC buffer grew to %d", cap(v.osc))
+	}
+	v.write([]byte("\x07x"))
+	if len(v.title) != maxOSC-2 {
+		t.Errorf("title is %d bytes", len(v.title))
+	}
+	if v.lines[0][0].ch != 'x' {
+		t.Errorf("text after the OSC is %q", v.lines[0][0].ch)
+	}
+}
+
+func TestVTLongCSI(t *testing.T) {
+	v := newVT(20, 5)
+	v.write([]byte("\x1b["))
+	for i := 0; i < 1<<16; i++ {
+		v.write([]byte("1;"))
+	}
+	if len(v.params) > maxParams || cap(v.params) > 2*maxParams {
+		t.Errorf("CSI parameters grew to %d", cap(v.params))
+	}
+	v.write([]byte("m"))
+
+	v.write([]byte("\x1b[99999999999999999999999;5H"))
+	if v.cy != 4 || v.cx != 4 {
+		t.Errorf("cursor is at (%d, %d), expected (4, 4)", v.cx, v.cy)
+	}
+	v.write([]byte("\x1b[2;3Hy"))
+	if v.lines[1][2].ch != 'y' {
+		t.Errorf("CSI after huge parameters is broken")
+	}
+}
+
+func TestVTWideRuneNarrow(t *testing.T) {
+	for _, mode := range []string{"", "\x1b[?7l", "\x1b[4h", "\x1b[?7l\x1b[4h"} {
+		v := newVT(1, 3)
+		v.write([]byte(mode + "世a世"))
+		if v.lines[0][0].ch == '世' || v.lines[0][0].ch == 0 {
+			t.Errorf("%q: wide rune in a 1 column terminal is %q", mode, v.lines[0][0].ch)
+		}
+	}
+	v := newVT(3, 2)
+	v.write([]byte("\x1b[?7lab世"))
+	if string([]rune{v.lines[0][0].ch, v.lines[0][1].ch}) != "a世" || v.lines[0][2].ch != 0 {
+		t.Errorf("wide rune at the end without autowrap is %q", []rune{v.lines[0][0].ch, v.lines[0][1].ch, v.lines[0][2].ch})
+	}
+	v = newVT(3, 2)
+	v.write([]byte("\x1b[4hab\x1b[H世"))
+	if v.lines[0][0].ch != '世' || v.lines[0][1].ch != 0 || v.lines[0][2].ch != 'a' {
+		t.Errorf("inserted wide rune is %q", []rune{v.lines[0][0].ch, v.lines[0][1].ch, v.lines[0][2].ch})
+	}
+}
+
+// lineText gets the text of the visible line, without the right halves of wide runes.
+func lineText(line []cell) string {
+	var b strings.Builder
+	for _, c := range line {
+		if c.ch != 0 {
+			b.WriteRune(c.ch)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func TestVTSGR(t *testing.T) {
+	v := newVT(20, 2)
+	v.write([]byte("\x1b[1;4;31;42ma\x1b[22;24mb\x1b[38;5;200;48;2;1;2;3mc\x1b[0;7;95md\x1b[me"))
+	tests := []struct {
+		fg, bg tcell.Color
+		attrs  tcell.AttrMask
+	}{
+		{tcell.PaletteColor(1), tcell.PaletteColor(2), tcell.AttrBold | tcell.AttrUnderline},
+		{tcell.PaletteColor(1), tcell.PaletteColor(2), 0},
+		{tcell.PaletteColor(200), tcell.NewRGBColor(1, 2, 3), 0},
+		{tcell.PaletteColor(13), tcell.ColorDefault, tcell.AttrReverse},
+		{tcell.ColorDefault, tcell.ColorDefault, 0},
+	}
+	for i, tt := range tests {
+		fg, bg, attrs := v.lines[0][i].style.Decompose()
+		if fg != tt.fg || bg != tt.bg || attrs != tt.attrs {
+			t.Errorf("cell %d is %v on %v with %v, expected %v on %v with %v", i, fg, bg, attrs, tt.fg, tt.bg, tt.attrs)
+		}
+	}
+}
+
+func TestVTCursorAndErase(t *testing.T) {
+	v := newVT(10, 4)
+	v.write([]byte("abcdefghij\r\n0123456789\x1b[3;4Hxyz"))
+	if v.cx != 6 || v.cy != 2 {
+		t.Errorf("cursor is at (%d, %d), expected (6, 2)", v.cx, v.cy)
+	}
+	v.write([]byte("\x1b[2A\x1b[3D\x1b[K"))
+	if got := lineText(v.lines[0]); got != "abc" {
+		t.Errorf("line erased to the end is %q", got)
+	}
+	v.write([]byte("\x1b[B\x1b[5G\x1b[1K"))
+	if got := lineText(v.lines[1]); got != "     56789" {
+		t.Errorf("line erased to the cursor is %q", got)
+	}
+	v.write([]byte("\x1b[2P"))
+	if got := lineText(v.lines[1]); got != "    6789" {
+		t.Errorf("line with deleted chars is %q", got)
+	}
+	v.write([]byte("\x1b[2@"))
+	if got := lineText(v.lines[1]); got != "      6789" {
+		t.Errorf("line with inserted blanks is %q", got)
+	}
+	v.write([]byte("\x1b[6n"))
+	if string(v.reply) != "\x1b[2;5R" {
+		t.Errorf("cursor position report is %q", v.reply)
+	}
+	v.write([]byte("\x1b[2J"))
+	for i, line := range v.lines {
+		if got := lineText(line); got != "" {
+			t.Errorf("line %d is %q after erasing the display", i, got)
+		}
+	}
+}
+
+func TestVTScrollRegion(t *testing.T) {
+	v := newVT(5, 5)
+	v.write([]byte("1\r\n2\r\n3\r\n4\r\n5\x1b[2;4r\x1b[4;1H\n"))
+	want := []string{"1", "3", "4", "", "5"}
+	for i, line := range v.lines {
+		if got := lineText(line); got != want[i] {
+			t.Errorf("line %d is %q, expected %q", i, got, want[i])
+		}
+	}
+	if len(v.scrollback) != 0 {
+		t.Errorf("%d lines scrolled out of the region went to the scrollback", len(v.scrollback))
+	}
+	v.write([]byte("\x1b[2;1H\x1bM"))
+	want = []string{"1", "", "3", "4", "5"}
+	for i, line := range v.lines {
+		if got := lineText(line); got != want[i] {
+			t.Errorf("line %d is %q after reverse index, expected %q", i, got, want[i])
+		}
+	}
+}
+
+func TestVTScrollback(t *testing.T) {
+	v := newVT(5, 2)
+	v.maxScrollback = 3
+	v.write([]byte("1\r\n2\r\n3\r\n4\r\n5\r\n6"))
+	var got []string
+	for _, line := range v.scrollback {
+		got = append(got, lineText(line))
+	}
+	if strings.Join(got, ",") != "2,3,4" {
+		t.Errorf("scrollback is %q", got)
+	}
+	v.write([]byte("\x1b[3J"))
+	if len(v.scrollback) != 0 {
+		t.Errorf("scrollback has %d lines after erasing it", len(v.scrollback))
+	}
+}
+
+func TestVTAltScreen(t *testing.T) {
+	v := newVT(10, 3)
+	v.write([]byte("main\x1b[?1049h\x1b[Halt\r\n\n\n\n"))
+	if got := lineText(v.lines[0]); got != "" {
+		t.Errorf("alternate screen line 0 is %q", got)
+	}
+	if len(v.scrollback) != 0 {
+		t.Errorf("alternate screen scrolled %d lines into the scrollback", len(v.scrollback))
+	}
+	v.write([]byte("\x1b[?1049l"))
+	if got := lineText(v.lines[0]); got != "main" {
+		t.Errorf("main screen line 0 is %q after the alternate screen", got)
+	}
+	if v.cx != 4 || v.cy != 0 {
+		t.Errorf("cursor is at (%d, %d), expected it restored to (4, 0)", v.cx, v.cy)
+	}
+}
+
+func TestVTResize(t *testing.T) {
+	v := newVT(10, 4)
+	v.write([]byte("1\r\n2\r\n3\r\n4567890123"))
+	v.resize(5, 2)
+	if got := lineText(v.lines[1]); got != "45678" {
+		t.Errorf("cursor line is %q after shrinking", got)
+	}
+	if len(v.scrollback) != 2 || lineText(v.scrollback[1]) != "2" {
+		t.Errorf("%d lines went to the scrollback after shrinking", len(v.scrollback))
+	}
+	if v.cx != 4 || v.cy != 1 {
+		t.Errorf("cursor is at (%d, %d) after shrinking, expected (4, 1)", v.cx, v.cy)
+	}
+	v.resize(8, 3)
+	if got := lineText(v.lines[1]); got != "45678" || len(v.lines[1]) != 8 {
+		t.Errorf("line is %q of %d cells after growing", got, len(v.lines[1]))
+	}
+	v.write([]byte("\x1b[3;8Hx"))
+	if v.lines[2][7].ch != 'x' {
+		t.Errorf("can't write to the grown area")
+	}
+}
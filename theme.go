@@ -0,0 +1,336 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"os"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// WindowTheme is the look of the windows and desktop, see WindowManager.SetTheme.
+type WindowTheme struct {
+	TitleAlign               int
+	ActiveCaptionTextColor   tcell.Color
+	ActiveCaptionColor       tcell.Color
+	InactiveCaptionTextColor tcell.Color
+	InactiveCaptionColor     tcell.Color
+	Border                   BorderSet // Zero value uses tview.Borders.
+	ActiveBorder             BorderSet // Border of the focused window, zero value uses Border.
+	ActiveBorderColor        tcell.Color
+	InactiveBorderColor      tcell.Color
+	ClientBackgroundColor    tcell.Color // Window background, behind the client; unless the window sets its own.
+	InactiveDim              float64     // How much to dim clients of inactive windows, 0 to 1.
+	GripColor                tcell.Color // Resize grip in the lower right corner.
+	MinimizeButton           string      // Caption button glyphs, empty to hide.
+	MaximizeButton           string
+	RestoreButton            string
+	CloseButton              string
+	Shadow                   bool        // Draw a shadow right and below windows.
+	ShadowColor              tcell.Color // Shadow on cells with the default background.
+	ShadowDim                float64     // How much the shadow darkens, 0 to 1; 0 uses 0.5.
+	DesktopColor             tcell.Color // Desktop background, unless the desktop sets its own.
+	NotifyTextColor          tcell.Color // Notification body and actions, on the client background.
+	NotifySuccessTextColor   tcell.Color // Notification captions by severity, see Desktop.Notify;
+	NotifySuccessColor       tcell.Color // ColorDefault uses the active caption colors.
+	NotifyWarningTextColor   tcell.Color
+	NotifyWarningColor       tcell.Color
+	NotifyErrorTextColor     tcell.Color
+	NotifyErrorColor         tcell.Color
+	Wallpaper                Wallpaper // Drawn on the desktop, can be nil.
+	Monochrome               bool      // No colors, active captions use reverse and bold.
+	// Fallbacks are the themes to use on screens with fewer colors,
+	// keyed by the most colors each one is for, such as 16 or 8; see ForColors.
+	Fallbacks map[int]*WindowTheme
+}
+
+// ForColors gets the variant of the theme for a screen with this many colors.
+// The fallback for the fewest colors which still covers the screen is used,
+// or the theme itself if the screen has more colors than every fallback.
+// A fallback without a wallpaper uses the theme's wallpaper.
+// Screens with 2 colors or less, or when NO_COLOR is set, get a Monochrome theme.
+// Note that the built-in themes have fallbacks, so when changing their colors in code,
+// also change or remove the fallbacks; LoadTheme does this for theme files.
+func (theme WindowTheme) ForColors(colors int) WindowTheme {
+	result := theme
+	best := -1
+	for maxColors, fallback := range theme.Fallbacks {
+		if fallback != nil && colors <= maxColors && (best == -1 || maxColors < best) {
+			result, best = *fallback, maxColors
+		}
+	}
+	if result.Wallpaper == nil {
+		result.Wallpaper = theme.Wallpaper
+	}
+	if colors <= 2 || result.Monochrome {
+		result.Monochrome = true
+		result.ActiveCaptionTextColor = tcell.ColorDefault
+		result.ActiveCaptionColor = tcell.ColorDefault
+		result.InactiveCaptionTextColor = tcell.ColorDefault
+		result.InactiveCaptionColor = tcell.ColorDefault
+		result.ActiveBorderColor = tcell.ColorDefault
+		result.InactiveBorderColor = tcell.ColorDefault
+		result.ClientBackgroundColor = tcell.ColorReset // Still fills, unlike ColorDefault.
+		result.GripColor = tcell.ColorDefault
+		result.ShadowColor = tcell.ColorDefault
+		result.DesktopColor = tcell.ColorReset
+		result.NotifyTextColor = tcell.ColorDefault
+		result.NotifySuccessTextColor = tcell.ColorDefault
+		result.NotifySuccessColor = tcell.ColorDefault
+		result.NotifyWarningTextColor = tcell.ColorDefault
+		result.NotifyWarningColor = tcell.ColorDefault
+		result.NotifyErrorTextColor = tcell.ColorDefault
+		result.NotifyErrorColor = tcell.ColorDefault
+	}
+	return result
+}
+
+// ownFallbacks gets the theme with its own copy of the fallbacks,
+// so changing them doesn't change the theme it was copied from, such as a built-in theme.
+func (theme WindowTheme) ownFallbacks() WindowTheme {
+	if theme.Fallbacks != nil {
+		fallbacks := make(map[int]*WindowTheme, len(theme.Fallbacks))
+		for maxColors, fallback := range theme.Fallbacks {
+			if fallback != nil {
+				fb := *fallback
+				fallback = &fb
+			}
+			fallbacks[maxColors] = fallback
+		}
+		theme.Fallbacks = fallbacks
+	}
+	return theme
+}
+
+// screenColors gets the number of colors the screen supports,
+// 0 when the user sets NO_COLOR to disable colors, see https://no-color.org/
+func screenColors(screen tcell.Screen) int {
+	if os.Getenv("NO_COLOR") != "" {
+		return 0
+	}
+	return screen.Colors()
+}
+
+// BorderSet is the set of runes used to draw a window border.
+type BorderSet struct {
+	Horizontal  rune
+	Vertical    rune
+	TopLeft     rune
+	TopRight    rune
+	BottomLeft  rune
+	BottomRight rune
+}
+
+// Built-in border sets.
+var (
+	SingleBorder  = BorderSet{'─', '│', '┌', '┐', '└', '┘'}
+	DoubleBorder  = BorderSet{'═', '║', '╔', '╗', '╚', '╝'}
+	RoundedBorder = BorderSet{'─', '│', '╭', '╮', '╰', '╯'}
+	HeavyBorder   = BorderSet{'━', '┃', '┏', '┓', '┗', '┛'}
+	ASCIIBorder   = BorderSet{'-', '|', '+', '+', '+', '+'}
+)
+
+// ClassicWindowTheme is the original tuix theme.
+// These colors were chosen to look decent and readable in most color counts.
+var ClassicWindowTheme = WindowTheme{
+	TitleAlign:               tview.AlignLeft,
+	ActiveCaptionTextColor:   tcell.ColorValid + 230,
+	ActiveCaptionColor:       tcell.ColorValid + 26,
+	InactiveCaptionTextColor: tcell.ColorValid + 15,
+	InactiveCaptionColor:     tcell.ColorValid + 239,
+	ActiveBorderColor:        tcell.ColorWhite,
+	InactiveBorderColor:      tcell.ColorWhite,
+	ClientBackgroundColor:    tcell.ColorBlack,
+	GripColor:                tcell.ColorValid + 226,
+	MinimizeButton:           "_",
+	MaximizeButton:           "▴",
+	RestoreButton:            "▾",
+	CloseButton:              "×",
+	ShadowColor:              tcell.ColorValid + 232,
+	DesktopColor:             tcell.ColorValid + 234,
+	NotifyTextColor:          tcell.ColorWhite,
+	NotifySuccessTextColor:   tcell.ColorValid + 231,
+	NotifySuccessColor:       tcell.ColorValid + 28,
+	NotifyWarningTextColor:   tcell.ColorValid + 16,
+	NotifyWarningColor:       tcell.ColorValid + 178,
+	NotifyErrorTextColor:     tcell.ColorValid + 231,
+	NotifyErrorColor:         tcell.ColorValid + 160,
+	Fallbacks: map[int]*WindowTheme{
+		16: &classic16WindowTheme,
+		8:  &classic8WindowTheme,
+	},
+}
+
+var classic16WindowTheme = WindowTheme{
+	TitleAlign:               tview.AlignLeft,
+	ActiveCaptionTextColor:   tcell.ColorWhite,
+	ActiveCaptionColor:       tcell.ColorNavy,
+	InactiveCaptionTextColor: tcell.ColorWhite,
+	InactiveCaptionColor:     tcell.ColorGray,
+	ActiveBorderColor:        tcell.ColorWhite,
+	InactiveBorderColor:      tcell.ColorSilver,
+	ClientBackgroundColor:    tcell.ColorBlack,
+	GripColor:                tcell.ColorYellow,
+	MinimizeButton:           "_",
+	MaximizeButton:           "▴",
+	RestoreButton:            "▾",
+	CloseButton:              "×",
+	ShadowColor:              tcell.ColorBlack,
+	DesktopColor:             tcell.ColorTeal,
+	NotifyTextColor:          tcell.ColorWhite,
+	NotifySuccessTextColor:   tcell.ColorBlack,
+	NotifySuccessColor:       tcell.ColorGreen,
+	NotifyWarningTextColor:   tcell.ColorBlack,
+	NotifyWarningColor:       tcell.ColorYellow,
+	NotifyErrorTextColor:     tcell.ColorWhite,
+	NotifyErrorColor:         tcell.ColorRed,
+}
+
+var classic8WindowTheme = WindowTheme{
+	TitleAlign:               tview.AlignLeft,
+	ActiveCaptionTextColor:   tcell.ColorSilver,
+	ActiveCaptionColor:       tcell.ColorNavy,
+	InactiveCaptionTextColor: tcell.ColorBlack,
+	InactiveCaptionColor:     tcell.ColorSilver,
+	ActiveBorderColor:        tcell.ColorSilver,
+	InactiveBorderColor:      tcell.ColorSilver,
+	ClientBackgroundColor:    tcell.ColorBlack,
+	GripColor:                tcell.ColorOlive,
+	MinimizeButton:           "_",
+	MaximizeButton:           "^",
+	RestoreButton:            "v",
+	CloseButton:              "x",
+	ShadowColor:              tcell.ColorBlack,
+	DesktopColor:             tcell.ColorTeal,
+	NotifyTextColor:          tcell.ColorSilver,
+	NotifySuccessTextColor:   tcell.ColorBlack,
+	NotifySuccessColor:       tcell.ColorGreen,
+	NotifyWarningTextColor:   tcell.ColorBlack,
+	NotifyWarningColor:       tcell.ColorOlive,
+	NotifyErrorTextColor:     tcell.ColorSilver,
+	NotifyErrorColor:         tcell.ColorMaroon,
+}
+
+// DarkWindowTheme is a low brightness theme with rounded borders and shadows.
+var DarkWindowTheme = WindowTheme{
+	TitleAlign:               tview.AlignCenter,
+	ActiveCaptionTextColor:   tcell.ColorValid + 255,
+	ActiveCaptionColor:       tcell.ColorValid + 238,
+	InactiveCaptionTextColor: tcell.ColorValid + 245,
+	InactiveCaptionColor:     tcell.ColorValid + 235,
+	Border:                   RoundedBorder,
+	ActiveBorderColor:        tcell.ColorValid + 250,
+	InactiveBorderColor:      tcell.ColorValid + 240,
+	ClientBackgroundColor:    tcell.ColorValid + 233,
+	InactiveDim:              0.3,
+	GripColor:                tcell.ColorValid + 110,
+	MinimizeButton:           "_",
+	MaximizeButton:           "▴",
+	RestoreButton:            "▾",
+	CloseButton:              "×",
+	Shadow:                   true,
+	ShadowColor:              tcell.ColorValid + 16,
+	ShadowDim:                0.6,
+	DesktopColor:             tcell.ColorValid + 232,
+	NotifyTextColor:          tcell.ColorValid + 252,
+	NotifySuccessTextColor:   tcell.ColorValid + 255,
+	NotifySuccessColor:       tcell.ColorValid + 22,
+	NotifyWarningTextColor:   tcell.ColorValid + 16,
+	NotifyWarningColor:       tcell.ColorValid + 136,
+	NotifyErrorTextColor:     tcell.ColorValid + 255,
+	NotifyErrorColor:         tcell.ColorValid + 124,
+	Fallbacks: map[int]*WindowTheme{
+		16: &dark16WindowTheme,
+	},
+}
+
+var dark16WindowTheme = WindowTheme{
+	TitleAlign:               tview.AlignCenter,
+	ActiveCaptionTextColor:   tcell.ColorWhite,
+	ActiveCaptionColor:       tcell.ColorGray,
+	InactiveCaptionTextColor: tcell.ColorSilver,
+	InactiveCaptionColor:     tcell.ColorBlack,
+	Border:                   RoundedBorder,
+	ActiveBorderColor:        tcell.ColorWhite,
+	InactiveBorderColor:      tcell.ColorGray,
+	ClientBackgroundColor:    tcell.ColorBlack,
+	GripColor:                tcell.ColorTeal,
+	MinimizeButton:           "_",
+	MaximizeButton:           "▴",
+	RestoreButton:            "▾",
+	CloseButton:              "×",
+	DesktopColor:             tcell.ColorBlack,
+	NotifyTextColor:          tcell.ColorSilver,
+	NotifySuccessTextColor:   tcell.ColorBlack,
+	NotifySuccessColor:       tcell.ColorGreen,
+	NotifyWarningTextColor:   tcell.ColorBlack,
+	NotifyWarningColor:       tcell.ColorOlive,
+	NotifyErrorTextColor:     tcell.ColorWhite,
+	NotifyErrorColor:         tcell.ColorMaroon,
+}
+
+// MonoWindowTheme only uses black, white and gray, with ASCII borders,
+// for terminals without good color or line drawing support.
+var MonoWindowTheme = WindowTheme{
+	TitleAlign:               tview.AlignLeft,
+	ActiveCaptionTextColor:   tcell.ColorBlack,
+	ActiveCaptionColor:       tcell.ColorWhite,
+	InactiveCaptionTextColor: tcell.ColorWhite,
+	InactiveCaptionColor:     tcell.ColorBlack,
+	Border:                   ASCIIBorder,
+	ActiveBorder:             BorderSet{'=', '|', '#', '#', '#', '#'},
+	ActiveBorderColor:        tcell.ColorWhite,
+	InactiveBorderColor:      tcell.ColorGray,
+	ClientBackgroundColor:    tcell.ColorBlack,
+	GripColor:                tcell.ColorWhite,
+	MinimizeButton:           "_",
+	MaximizeButton:           "^",
+	RestoreButton:            "v",
+	CloseButton:              "x",
+	ShadowColor:              tcell.ColorBlack,
+	DesktopColor:             tcell.ColorGray,
+	NotifyTextColor:          tcell.ColorWhite,
+	NotifySuccessTextColor:   tcell.ColorBlack,
+	NotifySuccessColor:       tcell.ColorWhite,
+	NotifyWarningTextColor:   tcell.ColorWhite,
+	NotifyWarningColor:       tcell.ColorGray,
+	NotifyErrorTextColor:     tcell.ColorBlack,
+	NotifyErrorColor:         tcell.ColorWhite,
+}
+
+// HighContrastWindowTheme uses heavy borders and strong colors for readability.
+var HighContrastWindowTheme = WindowTheme{
+	TitleAlign:               tview.AlignLeft,
+	ActiveCaptionTextColor:   tcell.ColorBlack,
+	ActiveCaptionColor:       tcell.ColorYellow,
+	InactiveCaptionTextColor: tcell.ColorWhite,
+	InactiveCaptionColor:     tcell.ColorNavy,
+	Border:                   HeavyBorder,
+	ActiveBorder:             DoubleBorder,
+	ActiveBorderColor:        tcell.ColorYellow,
+	InactiveBorderColor:      tcell.ColorWhite,
+	ClientBackgroundColor:    tcell.ColorBlack,
+	GripColor:                tcell.ColorFuchsia,
+	MinimizeButton:           "_",
+	MaximizeButton:           "▲",
+	RestoreButton:            "▼",
+	CloseButton:              "X",
+	Shadow:                   true,
+	ShadowColor:              tcell.ColorGray,
+	DesktopColor:             tcell.ColorBlack,
+	NotifyTextColor:          tcell.ColorWhite,
+	NotifySuccessTextColor:   tcell.ColorBlack,
+	NotifySuccessColor:       tcell.ColorLime,
+	NotifyWarningTextColor:   tcell.ColorBlack,
+	NotifyWarningColor:       tcell.ColorYellow,
+	NotifyErrorTextColor:     tcell.ColorWhite,
+	NotifyErrorColor:         tcell.ColorRed,
+}
+
+// DefaultWindowTheme is the default desktop theme.
+var DefaultWindowTheme = ClassicWindowTheme
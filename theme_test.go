@@ -0,0 +1,156 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+)
+
+// nopWallpaper draws nothing.
+type nopWallpaper struct{ name string }
+
+func (*nopWallpaper) DrawWallpaper(screen tcell.Screen, x, y, width, height int) {}
+
+func TestThemeForColors(t *testing.T) {
+	wallpaper := &nopWallpaper{"theme"}
+	theme := tuix.WindowTheme{
+		ActiveCaptionColor: tcell.PaletteColor(100),
+		Wallpaper:          wallpaper,
+		Fallbacks: map[int]*tuix.WindowTheme{
+			16: {ActiveCaptionColor: tcell.ColorNavy},
+			8:  {ActiveCaptionColor: tcell.ColorMaroon, Wallpaper: &nopWallpaper{"8"}},
+			4:  nil,
+		},
+	}
+	tests := []struct {
+		colors int
+		want   tcell.Color
+	}{
+		{1 << 24, tcell.PaletteColor(100)},
+		{256, tcell.PaletteColor(100)},
+		{17, tcell.PaletteColor(100)},
+		{16, tcell.ColorNavy},
+		{9, tcell.ColorNavy},
+		{8, tcell.ColorMaroon},
+		{3, tcell.ColorMaroon}, // The nil fallback is skipped.
+	}
+	for _, tt := range tests {
+		got := theme.ForColors(tt.colors)
+		if got.ActiveCaptionColor != tt.want || got.Monochrome {
+			t.Errorf("%d colors use %v, monochrome %v; expected %v", tt.colors, got.ActiveCaptionColor, got.Monochrome, tt.want)
+		}
+	}
+	if got := theme.ForColors(16); got.Wallpaper != wallpaper {
+		t.Error("fallback without a wallpaper doesn't use the theme's")
+	}
+	if got := theme.ForColors(8); got.Wallpaper == wallpaper {
+		t.Error("fallback's own wallpaper is not used")
+	}
+}
+
+func TestThemeMonochrome(t *testing.T) {
+	for _, colors := range []int{0, 1, 2} {
+		got := tuix.ClassicWindowTheme.ForColors(colors)
+		if !got.Monochrome || got.ActiveCaptionColor != tcell.ColorDefault || got.DesktopColor != tcell.ColorReset {
+			t.Errorf("%d colors are not monochrome", colors)
+		}
+	}
+	theme := tuix.DarkWindowTheme
+	theme.Monochrome = true
+	got := theme.ForColors(256)
+	if !got.Monochrome || got.ActiveCaptionTextColor != tcell.ColorDefault || got.NotifyErrorColor != tcell.ColorDefault {
+		t.Error("monochrome theme has colors")
+	}
+	if got.Border != theme.Border {
+		t.Error("monochrome theme lost its border")
+	}
+}
+
+// captionStyle draws a focused window and gets the style of its caption.
+func captionStyle(t *testing.T, theme tuix.WindowTheme) tcell.Style {
+	h := tuixtest.New(t, 30, 10)
+	defer h.Close()
+	h.Desktop.SetTheme(theme)
+	win := tuix.NewWindow()
+	win.SetTitle("A").SetBorder(true).SetRect(2, 2, 20, 6)
+	h.Desktop.AddWindow(win)
+	h.SetFocus(win)
+	_, _, style, _ := h.Screen.GetContent(10, 2)
+	return style
+}
+
+func TestThemeNoColor(t *testing.T) {
+	old, had := os.LookupEnv("NO_COLOR")
+	defer func() {
+		if had {
+			os.Setenv("NO_COLOR", old)
+		} else {
+			os.Unsetenv("NO_COLOR")
+		}
+	}()
+	theme := tuix.ClassicWindowTheme
+	os.Unsetenv("NO_COLOR")
+	if _, bg, _ := captionStyle(t, theme).Decompose(); bg != theme.ActiveCaptionColor {
+		t.Errorf("caption background is %v without NO_COLOR", bg)
+	}
+	os.Setenv("NO_COLOR", "1")
+	if style := captionStyle(t, theme); style != tcell.StyleDefault.Reverse(true).Bold(true) {
+		t.Errorf("caption style is %v with NO_COLOR", style)
+	}
+}
+
+func TestThemeOwnFallbacks(t *testing.T) {
+	fallback := tuix.ClassicWindowTheme.Fallbacks[8]
+	d := tuix.NewDesktop()
+	d.SetTheme(tuix.ClassicWindowTheme)
+	theme := d.GetTheme()
+	theme.Fallbacks[8].ActiveCaptionColor = tcell.ColorRed
+	delete(theme.Fallbacks, 8)
+	if tuix.ClassicWindowTheme.Fallbacks[8] != fallback || fallback.ActiveCaptionColor == tcell.ColorRed {
+		t.Error("changing the desktop's fallbacks changed the built-in theme")
+	}
+	tuix.NewWindowManager(tuix.ClassicWindowTheme).GetTheme().Fallbacks[16].DesktopColor = tcell.ColorRed
+	if tuix.ClassicWindowTheme.Fallbacks[16].DesktopColor == tcell.ColorRed {
+		t.Error("changing a window manager's fallbacks changed the built-in theme")
+	}
+}
+
+func TestLoadThemeFallbacks(t *testing.T) {
+	// Without changing colors, the base theme's fallbacks are kept, as a copy.
+	theme, err := tuix.LoadTheme(strings.NewReader(`shadow = true`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(theme.Fallbacks) != len(tuix.ClassicWindowTheme.Fallbacks) {
+		t.Fatalf("theme has %d fallbacks", len(theme.Fallbacks))
+	}
+	if theme.ForColors(8).ActiveCaptionColor != tuix.ClassicWindowTheme.ForColors(8).ActiveCaptionColor {
+		t.Error("8 colors don't use the base theme's fallback")
+	}
+	delete(theme.Fallbacks, 8)
+	if tuix.ClassicWindowTheme.Fallbacks[8] == nil {
+		t.Error("changing the loaded fallbacks changed the base theme")
+	}
+
+	// Changing a color drops them, so screens with fewer colors still get the change.
+	theme, err = tuix.LoadTheme(strings.NewReader(`{"base": "dark", "desktop_color": "purple"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Fallbacks != nil {
+		t.Errorf("theme with a changed color kept %d base fallbacks", len(theme.Fallbacks))
+	}
+	if got := theme.ForColors(16).DesktopColor; got != tcell.ColorPurple {
+		t.Errorf("16 color desktop is %v, expected the loaded color", got)
+	}
+}
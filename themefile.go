@@ -0,0 +1,288 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// LoadTheme parses a theme from JSON or TOML, the format is detected.
+// Keys are the WindowTheme field names in snake_case, such as
+// active_caption_color; any key left out comes from the "base" theme,
+// which is one of classic (the default), dark, mono or high_contrast.
+// Colors can be names ("navy"), hex ("#000080") or 256-color indices (4).
+// Borders are one of single, double, rounded, heavy or ascii,
+// or six runes: horizontal, vertical, top left, top right, bottom left, bottom right.
+// Amounts such as inactive_dim are numbers from 0 to 1.
+// Fallbacks for screens with fewer colors go in a "fallbacks" table keyed by
+// the most colors each is for, such as "16" or "8", with the same keys;
+// they start from the loaded theme. Without it, the base theme's fallbacks are kept
+// only if no colors are changed, since they would undo the changes.
+// Set monochrome to true to use attributes instead of colors.
+// Wallpapers are not loaded from files, set them afterwards.
+func LoadTheme(r io.Reader) (WindowTheme, error) {
+	br := bufio.NewReader(r)
+	var values map[string]interface{}
+	if isJSON(br) {
+		dec := json.NewDecoder(br)
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return WindowTheme{}, fmt.Errorf("theme: %v", err)
+		}
+	} else {
+		if _, err := toml.DecodeReader(br, &values); err != nil {
+			return WindowTheme{}, fmt.Errorf("theme: %v", err)
+		}
+	}
+	theme := ClassicWindowTheme
+	if base, ok := values["base"]; ok {
+		name, _ := base.(string)
+		builtin := builtinThemes[strings.ToLower(name)]
+		if builtin == nil {
+			return WindowTheme{}, fmt.Errorf("theme: unknown base theme %v", base)
+		}
+		theme = *builtin
+	}
+	theme = theme.ownFallbacks()
+	return themeFromValues(values, theme)
+}
+
+// isJSON peeks at the first non-space byte to see if it's a JSON object.
+func isJSON(br *bufio.Reader) bool {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return false
+		}
+		if !unicode.IsSpace(rune(c)) {
+			br.UnreadByte()
+			return c == '{'
+		}
+	}
+}
+
+var builtinThemes = map[string]*WindowTheme{
+	"classic":       &ClassicWindowTheme,
+	"dark":          &DarkWindowTheme,
+	"mono":          &MonoWindowTheme,
+	"high_contrast": &HighContrastWindowTheme,
+}
+
+var builtinBorders = map[string]BorderSet{
+	"single":  SingleBorder,
+	"double":  DoubleBorder,
+	"rounded": RoundedBorder,
+	"heavy":   HeavyBorder,
+	"ascii":   ASCIIBorder,
+}
+
+// themeFromValues updates the theme from the decoded values.
+func themeFromValues(values map[string]interface{}, theme WindowTheme) (WindowTheme, error) {
+	colors := map[string]*tcell.Color{
+		"active_caption_text_color":   &theme.ActiveCaptionTextColor,
+		"active_caption_color":        &theme.ActiveCaptionColor,
+		"inactive_caption_text_color": &theme.InactiveCaptionTextColor,
+		"inactive_caption_color":      &theme.InactiveCaptionColor,
+		"active_border_color":         &theme.ActiveBorderColor,
+		"inactive_border_color":       &theme.InactiveBorderColor,
+		"client_background_color":     &theme.ClientBackgroundColor,
+		"grip_color":                  &theme.GripColor,
+		"shadow_color":                &theme.ShadowColor,
+		"desktop_color":               &theme.DesktopColor,
+		"notify_text_color":           &theme.NotifyTextColor,
+		"notify_success_text_color":   &theme.NotifySuccessTextColor,
+		"notify_success_color":        &theme.NotifySuccessColor,
+		"notify_warning_text_color":   &theme.NotifyWarningTextColor,
+		"notify_warning_color":        &theme.NotifyWarningColor,
+		"notify_error_text_color":     &theme.NotifyErrorTextColor,
+		"notify_error_color":          &theme.NotifyErrorColor,
+	}
+	glyphs := map[string]*string{
+		"minimize_button": &theme.MinimizeButton,
+		"maximize_button": &theme.MaximizeButton,
+		"restore_button":  &theme.RestoreButton,
+		"close_button":    &theme.CloseButton,
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // Consistent errors.
+	for _, key := range keys {
+		value := values[key]
+		var err error
+		if colors[key] != nil {
+			theme.Fallbacks = nil // Made for the base theme's colors.
+		}
+		switch key {
+		case "base":
+		case "title_align":
+			theme.TitleAlign, err = parseAlign(value)
+		case "border":
+			theme.Border, err = parseBorder(value)
+		case "active_border":
+			theme.ActiveBorder, err = parseBorder(value)
+		case "inactive_dim":
+			theme.InactiveDim, err = parseAmount(value)
+		case "shadow_dim":
+			theme.ShadowDim, err = parseAmount(value)
+		case "shadow":
+			theme.Shadow, err = parseBool(value)
+		case "monochrome":
+			theme.Monochrome, err = parseBool(value)
+		case "fallbacks":
+			// After everything else, since fallbacks start from this theme.
+		default:
+			if color := colors[key]; color != nil {
+				*color, err = parseColor(value)
+			} else if glyph := glyphs[key]; glyph != nil {
+				var ok bool
+				if *glyph, ok = value.(string); !ok {
+					err = fmt.Errorf("expected a string, not %v", value)
+				}
+			} else {
+				err = fmt.Errorf("unknown key")
+			}
+		}
+		if err != nil {
+			return WindowTheme{}, fmt.Errorf("theme: %s: %v", key, err)
+		}
+	}
+	if value, ok := values["fallbacks"]; ok {
+		fallbacks, ok := value.(map[string]interface{})
+		if !ok {
+			return WindowTheme{}, fmt.Errorf("theme: fallbacks: expected a table")
+		}
+		base := theme
+		base.Fallbacks = nil
+		theme.Fallbacks = make(map[int]*WindowTheme, len(fallbacks))
+		for key, value := range fallbacks {
+			maxColors, err := strconv.Atoi(key)
+			if err != nil {
+				return WindowTheme{}, fmt.Errorf("theme: fallbacks: %q is not a number of colors", key)
+			}
+			fbValues, ok := value.(map[string]interface{})
+			if !ok {
+				return WindowTheme{}, fmt.Errorf("theme: fallbacks: %s: expected a table", key)
+			}
+			if _, ok := fbValues["base"]; ok {
+				return WindowTheme{}, fmt.Errorf("theme: fallbacks: %s: base is not allowed", key)
+			}
+			if _, ok := fbValues["fallbacks"]; ok {
+				return WindowTheme{}, fmt.Errorf("theme: fallbacks: %s: fallbacks are not allowed", key)
+			}
+			fallback, err := themeFromValues(fbValues, base)
+			if err != nil {
+				return WindowTheme{}, fmt.Errorf("theme: fallbacks: %s: %v", key, strings.TrimPrefix(err.Error(), "theme: "))
+			}
+			theme.Fallbacks[maxColors] = &fallback
+		}
+	}
+	return theme, nil
+}
+
+func parseBool(value interface{}) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected true or false, not %v", value)
+	}
+	return b, nil
+}
+
+func parseAlign(value interface{}) (int, error) {
+	name, _ := value.(string)
+	switch strings.ToLower(name) {
+	case "left":
+		return tview.AlignLeft, nil
+	case "center":
+		return tview.AlignCenter, nil
+	case "right":
+		return tview.AlignRight, nil
+	}
+	return 0, fmt.Errorf("expected left, center or right, not %v", value)
+}
+
+func parseBorder(value interface{}) (BorderSet, error) {
+	name, _ := value.(string)
+	if border, ok := builtinBorders[strings.ToLower(name)]; ok {
+		return border, nil
+	}
+	if name == "" || name == "none" || name == "default" {
+		return BorderSet{}, nil // tview.Borders
+	}
+	if r := []rune(name); len(r) == 6 {
+		return BorderSet{r[0], r[1], r[2], r[3], r[4], r[5]}, nil
+	}
+	return BorderSet{}, fmt.Errorf("unknown border %v", value)
+}
+
+// parseAmount parses a number from 0 to 1.
+func parseAmount(value interface{}) (float64, error) {
+	var f float64
+	switch v := value.(type) {
+	case json.Number:
+		var err error
+		if f, err = v.Float64(); err != nil {
+			return 0, fmt.Errorf("invalid number %v", v)
+		}
+	case float64:
+		f = v
+	case int64:
+		f = float64(v)
+	default:
+		return 0, fmt.Errorf("expected a number, not %v", value)
+	}
+	if f < 0 || f > 1 {
+		return 0, fmt.Errorf("%v is not from 0 to 1", f)
+	}
+	return f, nil
+}
+
+// parseColor parses a color name, hex color or 256-color index.
+func parseColor(value interface{}) (tcell.Color, error) {
+	var index int64
+	switch v := value.(type) {
+	case string:
+		name := strings.ToLower(strings.TrimSpace(v))
+		if name == "default" || name == "" {
+			return tcell.ColorDefault, nil
+		}
+		if n, err := strconv.ParseInt(name, 10, 32); err == nil {
+			index = n
+			break
+		}
+		if color := tcell.GetColor(name); color != tcell.ColorDefault {
+			return color, nil
+		}
+		return tcell.ColorDefault, fmt.Errorf("unknown color %q", v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return tcell.ColorDefault, fmt.Errorf("invalid color index %v", v)
+		}
+		index = n
+	case int64:
+		index = v
+	default:
+		return tcell.ColorDefault, fmt.Errorf("invalid color %v", value)
+	}
+	if index < 0 || index > 255 {
+		return tcell.ColorDefault, fmt.Errorf("color index %d out of range", index)
+	}
+	return tcell.PaletteColor(int(index)), nil
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+const jsonTheme = `{
+	"base": "dark",
+	"title_align": "center",
+	"active_caption_color": "#102030",
+	"active_caption_text_color": "yellow",
+	"inactive_caption_color": 17,
+	"inactive_caption_text_color": "240",
+	"border": "double",
+	"active_border": "abcdef",
+	"inactive_dim": 0.25,
+	"shadow": true,
+	"close_button": "X",
+	"fallbacks": {
+		"16": {"active_caption_color": "navy", "border": "ascii"},
+		"8": {"active_caption_color": 4, "shadow": false}
+	}
+}`
+
+const tomlTheme = `
+base = "dark"
+title_align = "center"
+active_caption_color = "#102030"
+active_caption_text_color = "yellow"
+inactive_caption_color = 17
+inactive_caption_text_color = "240"
+border = "double"
+active_border = "abcdef"
+inactive_dim = 0.25
+shadow = true
+close_button = "X"
+
+[fallbacks.16]
+active_caption_color = "navy"
+border = "ascii"
+
+[fallbacks.8]
+active_caption_color = 4
+shadow = false
+`
+
+func TestLoadTheme(t *testing.T) {
+	for format, text := range map[string]string{"JSON": jsonTheme, "TOML": tomlTheme} {
+		theme, err := tuix.LoadTheme(strings.NewReader(text))
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if theme.TitleAlign != tview.AlignCenter {
+			t.Errorf("%s: title align is %d", format, theme.TitleAlign)
+		}
+		if theme.ActiveCaptionColor != tcell.NewRGBColor(0x10, 0x20, 0x30) {
+			t.Errorf("%s: hex color is %v", format, theme.ActiveCaptionColor)
+		}
+		if theme.ActiveCaptionTextColor != tcell.ColorYellow {
+			t.Errorf("%s: named color is %v", format, theme.ActiveCaptionTextColor)
+		}
+		if theme.InactiveCaptionColor != tcell.PaletteColor(17) || theme.InactiveCaptionTextColor != tcell.PaletteColor(240) {
+			t.Errorf("%s: color indices are %v and %v", format, theme.InactiveCaptionColor, theme.InactiveCaptionTextColor)
+		}
+		if theme.Border != tuix.DoubleBorder || theme.ActiveBorder != (tuix.BorderSet{'a', 'b', 'c', 'd', 'e', 'f'}) {
+			t.Errorf("%s: borders are %v and %v", format, theme.Border, theme.ActiveBorder)
+		}
+		if theme.InactiveDim != 0.25 || !theme.Shadow || theme.CloseButton != "X" {
+			t.Errorf("%s: inactive dim %v, shadow %v, close button %q", format, theme.InactiveDim, theme.Shadow, theme.CloseButton)
+		}
+		// Keys left out come from the base theme.
+		if theme.DesktopColor != tuix.DarkWindowTheme.DesktopColor || theme.MinimizeButton != tuix.DarkWindowTheme.MinimizeButton {
+			t.Errorf("%s: desktop color %v and minimize button %q are not from the base", format, theme.DesktopColor, theme.MinimizeButton)
+		}
+
+		if len(theme.Fallbacks) != 2 {
+			t.Fatalf("%s: %d fallbacks", format, len(theme.Fallbacks))
+		}
+		fb16, fb8 := theme.Fallbacks[16], theme.Fallbacks[8]
+		if fb16 == nil || fb8 == nil {
+			t.Fatalf("%s: fallbacks are for %v", format, theme.Fallbacks)
+		}
+		if fb16.ActiveCaptionColor != tcell.ColorNavy || fb16.Border != tuix.ASCIIBorder || !fb16.Shadow {
+			t.Errorf("%s: 16 color fallback is %v, %v, shadow %v", format, fb16.ActiveCaptionColor, fb16.Border, fb16.Shadow)
+		}
+		if fb8.ActiveCaptionColor != tcell.PaletteColor(4) || fb8.Shadow || fb8.Border != tuix.DoubleBorder {
+			t.Errorf("%s: 8 color fallback is %v, %v, shadow %v", format, fb8.ActiveCaptionColor, fb8.Border, fb8.Shadow)
+		}
+		// Fallbacks start from the loaded theme.
+		if fb8.ActiveCaptionTextColor != tcell.ColorYellow || fb8.Fallbacks != nil {
+			t.Errorf("%s: 8 color fallback doesn't start from the theme", format)
+		}
+	}
+}
+
+func TestLoadThemeDefaultBase(t *testing.T) {
+	theme, err := tuix.LoadTheme(strings.NewReader(`shadow_dim = 0.5`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.ShadowDim != 0.5 || theme.DesktopColor != tuix.ClassicWindowTheme.DesktopColor {
+		t.Errorf("theme without a base is not classic")
+	}
+	theme, err = tuix.LoadTheme(strings.NewReader(`{"base": "high_contrast", "monochrome": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !theme.Monochrome || theme.ActiveCaptionColor != tuix.HighContrastWindowTheme.ActiveCaptionColor {
+		t.Errorf("high contrast base is not used")
+	}
+}
+
+func TestLoadThemeErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{"active_caption_color": "notacolor"}`, "active_caption_color: unknown color"},
+		{`active_caption_color = 256`, "active_caption_color: color index 256 out of range"},
+		{`{"desktop_color": true}`, "desktop_color: invalid color"},
+		{`{"colour": "red"}`, "colour: unknown key"},
+		{`base = "fancy"`, "unknown base theme"},
+		{`border = "wavy"`, "border: unknown border"},
+		{`inactive_dim = 2`, "inactive_dim: 2 is not from 0 to 1"},
+		{`shadow = "yes"`, "shadow: expected true or false"},
+		{`{"fallbacks": 16}`, "fallbacks: expected a table"},
+		{`{"fallbacks": {"many": {}}}`, `fallbacks: "many" is not a number of colors`},
+		{`{"fallbacks": {"16": {"base": "dark"}}}`, "fallbacks: 16: base is not allowed"},
+		{`{"fallbacks": {"16": {"fallbacks": {}}}}`, "fallbacks: 16: fallbacks are not allowed"},
+		{`{"fallbacks": {"8": {"grip_color": "nope"}}}`, "fallbacks: 8: grip_color: unknown color"},
+		{`{"shadow": }`, "theme: "},
+		{`shadow = `, "theme: "},
+	}
+	for _, tt := range tests {
+		_, err := tuix.LoadTheme(strings.NewReader(tt.text))
+		if err == nil || !strings.HasPrefix(err.Error(), "theme: ") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error is %v, expected %q", tt.text, err, tt.want)
+		}
+	}
+}
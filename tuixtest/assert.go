@@ -0,0 +1,88 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuixtest
+
+import (
+	"strings"
+
+	"github.com/millerlogic/tuix"
+	"github.com/rivo/tview"
+)
+
+// AssertRect checks the rect of the window.
+func (h *Harness) AssertRect(win *tuix.Window, x, y, width, height int) {
+	h.tb.Helper()
+	wx, wy, ww, wh := win.GetRect()
+	if wx != x || wy != y || ww != width || wh != height {
+		h.tb.Errorf("window %q rect is (%d, %d, %d, %d), expected (%d, %d, %d, %d)",
+			win.GetTitle(), wx, wy, ww, wh, x, y, width, height)
+	}
+}
+
+// AssertState checks the state of the window.
+func (h *Harness) AssertState(win *tuix.Window, state tuix.WindowState) {
+	h.tb.Helper()
+	if win.GetState() != state {
+		h.tb.Errorf("window %q state is %v, expected %v", win.GetTitle(), win.GetState(), state)
+	}
+}
+
+// AssertZOrder checks the windows of the desktop, from the bottom to the top.
+func (h *Harness) AssertZOrder(wins ...*tuix.Window) {
+	h.tb.Helper()
+	titles := func(wins []*tuix.Window) string {
+		s := make([]string, len(wins))
+		for i, win := range wins {
+			s[i] = win.GetTitle()
+		}
+		return strings.Join(s, ", ")
+	}
+	got := h.Desktop.GetWindows()
+	ok := len(got) == len(wins)
+	for i := 0; ok && i < len(wins); i++ {
+		ok = got[i] == wins[i]
+	}
+	if !ok {
+		h.tb.Errorf("z-order is [%s], expected [%s]", titles(got), titles(wins))
+	}
+}
+
+// AssertTop checks that the window is at the top of the z-order.
+func (h *Harness) AssertTop(win *tuix.Window) {
+	h.tb.Helper()
+	if top := h.Desktop.TopWindow(); top != win {
+		title := "<none>"
+		if top != nil {
+			title = top.GetTitle()
+		}
+		h.tb.Errorf("top window is %q, expected %q", title, win.GetTitle())
+	}
+}
+
+// AssertFocus checks that the primitive has focus, it can be a window containing the focus.
+func (h *Harness) AssertFocus(p tview.Primitive) {
+	h.tb.Helper()
+	if !p.HasFocus() {
+		h.tb.Errorf("%T does not have focus, %T does", p, h.GetFocus())
+	}
+}
+
+// AssertText checks the text on the screen at the position.
+func (h *Harness) AssertText(x, y int, text string) {
+	h.tb.Helper()
+	if got := h.TextAt(x, y, len([]rune(text))); got != text {
+		h.tb.Errorf("text at (%d, %d) is %q, expected %q", x, y, got, text)
+	}
+}
+
+// AssertContains checks that the text is somewhere on a line of the screen.
+func (h *Harness) AssertContains(text string) {
+	h.tb.Helper()
+	if !strings.Contains(h.Text(), text) {
+		h.tb.Errorf("screen does not contain %q:\n%s", text, h.Text())
+	}
+}
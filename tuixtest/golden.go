@@ -0,0 +1,117 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuixtest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+var update = flag.Bool("tuixtest.update", false, "Update tuixtest golden files")
+
+// Updating reports if golden files are being written instead of checked,
+// with go test -tuixtest.update or TUIXTEST_UPDATE=1.
+func Updating() bool {
+	return *update || os.Getenv("TUIXTEST_UPDATE") == "1"
+}
+
+// Snapshot gets the cells of the screen as text: the screen's lines,
+// then the same lines with a letter per cell for its style, then what the letters are.
+func (h *Harness) Snapshot() string {
+	sw, sh := h.Screen.Size()
+	var sb strings.Builder
+	sb.WriteString(h.Text())
+	sb.WriteString("\n--- styles\n")
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	var styles []tcell.Style
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			_, style := h.Cell(x, y)
+			i := 0
+			for i < len(styles) && styles[i] != style {
+				i++
+			}
+			if i == len(styles) {
+				styles = append(styles, style)
+			}
+			if i < len(letters) {
+				sb.WriteByte(letters[i])
+			} else {
+				sb.WriteByte('?')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("--- legend\n")
+	for i, style := range styles {
+		if i == len(letters) {
+			break
+		}
+		fmt.Fprintf(&sb, "%c: %s\n", letters[i], styleString(style))
+	}
+	return sb.String()
+}
+
+func styleString(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	s := "fg=" + colorString(fg) + " bg=" + colorString(bg)
+	for _, a := range []struct {
+		attr tcell.AttrMask
+		name string
+	}{
+		{tcell.AttrBold, "bold"},
+		{tcell.AttrBlink, "blink"},
+		{tcell.AttrReverse, "reverse"},
+		{tcell.AttrUnderline, "underline"},
+		{tcell.AttrDim, "dim"},
+		{tcell.AttrItalic, "italic"},
+		{tcell.AttrStrikeThrough, "strikethrough"},
+	} {
+		if attrs&a.attr != 0 {
+			s += " " + a.name
+		}
+	}
+	return s
+}
+
+func colorString(color tcell.Color) string {
+	if color == tcell.ColorDefault {
+		return "default"
+	}
+	return fmt.Sprintf("#%06x", color.Hex())
+}
+
+// AssertGolden compares the screen's Snapshot to testdata/name.golden,
+// or writes the file when Updating.
+func (h *Harness) AssertGolden(name string) {
+	h.tb.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := h.Snapshot()
+	if Updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			h.tb.Fatalf("tuixtest: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			h.tb.Fatalf("tuixtest: %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		h.tb.Fatalf("tuixtest: %v (run with -tuixtest.update to create it)", err)
+		return
+	}
+	if string(want) != got {
+		h.tb.Errorf("screen does not match %s:\n%s\nexpected:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package tuixtest runs a desktop on a simulation screen for tests,
+// with helpers to send input and check what's on the screen.
+// Every input is handled and drawn right away, there's no application or goroutines.
+//
+//	h := tuixtest.New(t, 80, 24)
+//	win := tuix.NewWindow().SetTitle("Test")
+//	win.SetBorder(true).SetRect(2, 2, 20, 8)
+//	h.Desktop.AddWindow(win)
+//	h.Drag(4, 2, 10, 5)
+//	h.AssertRect(win, 8, 5, 20, 8)
+package tuixtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/internal/driver"
+	"github.com/rivo/tview"
+)
+
+// Harness is a desktop on a simulation screen.
+type Harness struct {
+	Screen  tcell.SimulationScreen
+	Desktop *tuix.Desktop
+	tb      testing.TB
+	drv     *driver.Driver
+	now     time.Time // Fake clock for double clicks.
+	buttons tcell.ButtonMask
+}
+
+// New creates a harness with a new desktop on a screen of the size.
+func New(tb testing.TB, width, height int) *Harness {
+	return NewWithDesktop(tb, tuix.NewDesktop(), width, height)
+}
+
+// NewWithDesktop creates a harness for the desktop on a screen of the size,
+// such as a desktop with a custom window manager.
+func NewWithDesktop(tb testing.TB, d *tuix.Desktop, width, height int) *Harness {
+	tb.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		tb.Fatalf("tuixtest: %v", err)
+	}
+	screen.SetSize(width, height)
+	h := &Harness{
+		Screen:  screen,
+		Desktop: d,
+		tb:      tb,
+		drv:     driver.New(d),
+		now:     time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	h.drv.Now = func() time.Time { return h.now }
+	h.drv.SetFocus(d)
+	h.Draw()
+	return h
+}
+
+// Close finalizes the screen.
+func (h *Harness) Close() {
+	h.Screen.Fini()
+}
+
+// Draw draws the desktop filling the screen, the input helpers do this after each event.
+func (h *Harness) Draw() {
+	h.drv.Draw(h.Screen)
+}
+
+// Resize changes the size of the screen and draws.
+func (h *Harness) Resize(width, height int) {
+	h.Screen.SetSize(width, height)
+	h.Draw()
+}
+
+// SetFocus focuses the primitive like Application.SetFocus, and draws.
+func (h *Harness) SetFocus(p tview.Primitive) {
+	h.drv.SetFocus(p)
+	h.Draw()
+}
+
+// GetFocus gets the focused primitive.
+func (h *Harness) GetFocus() tview.Primitive {
+	return h.drv.GetFocus()
+}
+
+// Event handles any event, such as from tcell.NewEventKey, and draws.
+func (h *Harness) Event(event tcell.Event) {
+	h.drv.HandleEvent(event)
+	h.Draw()
+}
+
+// Key sends a key with modifiers, use tcell.KeyRune with r for runes.
+func (h *Harness) Key(key tcell.Key, r rune, mod tcell.ModMask) {
+	h.Event(tcell.NewEventKey(key, r, mod))
+}
+
+// Type sends each rune of the text as a key, newlines as Enter.
+func (h *Harness) Type(text string) {
+	for _, r := range text {
+		if r == '\n' {
+			h.Key(tcell.KeyEnter, 0, tcell.ModNone)
+		} else {
+			h.Key(tcell.KeyRune, r, tcell.ModNone)
+		}
+	}
+}
+
+// Mouse sends a mouse event with the buttons held at the position.
+func (h *Harness) Mouse(x, y int, buttons tcell.ButtonMask, mod tcell.ModMask) {
+	h.buttons = buttons
+	h.Event(tcell.NewEventMouse(x, y, buttons, mod))
+}
+
+// MouseMove moves the mouse, keeping any buttons held.
+func (h *Harness) MouseMove(x, y int) {
+	h.Mouse(x, y, h.buttons, tcell.ModNone)
+}
+
+// Click clicks the left button at the position.
+// Clicks are far enough apart in time to not be double clicks.
+func (h *Harness) Click(x, y int) {
+	h.now = h.now.Add(time.Second)
+	h.Mouse(x, y, tcell.Button1, tcell.ModNone)
+	h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+// RightClick clicks the right button at the position.
+func (h *Harness) RightClick(x, y int) {
+	h.now = h.now.Add(time.Second)
+	h.Mouse(x, y, tcell.Button3, tcell.ModNone)
+	h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+// DoubleClick double clicks the left button at the position.
+func (h *Harness) DoubleClick(x, y int) {
+	h.Click(x, y)
+	h.now = h.now.Add(tview.DoubleClickInterval / 2)
+	h.Mouse(x, y, tcell.Button1, tcell.ModNone)
+	h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+// Drag presses the left button at from, moves one cell at a time to to, and releases.
+func (h *Harness) Drag(fromX, fromY, toX, toY int) {
+	h.DragButton(tcell.Button1, tcell.ModNone, fromX, fromY, toX, toY)
+}
+
+// DragButton drags with the buttons and modifiers held.
+func (h *Harness) DragButton(buttons tcell.ButtonMask, mod tcell.ModMask, fromX, fromY, toX, toY int) {
+	h.now = h.now.Add(time.Second)
+	h.Mouse(fromX, fromY, buttons, mod)
+	x, y := fromX, fromY
+	for x != toX || y != toY {
+		x += sign(toX - x)
+		y += sign(toY - y)
+		h.Mouse(x, y, buttons, mod)
+	}
+	h.Mouse(x, y, tcell.ButtonNone, mod)
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	if n > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Wheel scrolls the mouse wheel at the position, up if lines is negative.
+func (h *Harness) Wheel(x, y, lines int) {
+	button := tcell.WheelDown
+	if lines < 0 {
+		button, lines = tcell.WheelUp, -lines
+	}
+	for i := 0; i < lines; i++ {
+		h.Mouse(x, y, button, tcell.ModNone)
+	}
+	h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+// Cell gets the rune and style at the position of the screen.
+func (h *Harness) Cell(x, y int) (rune, tcell.Style) {
+	c, _, style, _ := h.Screen.GetContent(x, y)
+	return c, style
+}
+
+// Line gets the text of a line of the screen, without trailing spaces.
+func (h *Harness) Line(y int) string {
+	w, _ := h.Screen.Size()
+	var sb strings.Builder
+	for x := 0; x < w; x++ {
+		c, _, _, width := h.Screen.GetContent(x, y)
+		if c == 0 {
+			c = ' '
+		}
+		sb.WriteRune(c)
+		if width > 1 {
+			x += width - 1
+		}
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// Text gets the text of the screen, one line per row.
+func (h *Harness) Text() string {
+	_, sh := h.Screen.Size()
+	lines := make([]string, sh)
+	for y := range lines {
+		lines[y] = h.Line(y)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TextAt gets n cells of text at the position.
+func (h *Harness) TextAt(x, y, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		c, _ := h.Cell(x+i, y)
+		if c == 0 {
+			c = ' '
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
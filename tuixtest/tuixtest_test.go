@@ -0,0 +1,145 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuixtest_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+// newWindow adds a window with an input field to the harness's desktop.
+func newWindow(h *tuixtest.Harness, title string, x, y, width, height int) (*tuix.Window, *tview.InputField) {
+	win := tuix.NewWindow().SetResizable(true)
+	win.SetTitle(title)
+	win.SetBorder(true).SetRect(x, y, width, height)
+	in := tview.NewInputField()
+	win.SetClient(in, true)
+	h.Desktop.AddWindow(win)
+	h.Draw()
+	return win, in
+}
+
+func TestCaptionDrag(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	win, _ := newWindow(h, "A", 2, 2, 20, 8)
+	h.Drag(6, 2, 16, 7)
+	h.AssertRect(win, 12, 7, 20, 8)
+	h.AssertText(13, 7, "A")
+}
+
+func TestClickToRaise(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	a, ain := newWindow(h, "A", 2, 2, 20, 8)
+	b, bin := newWindow(h, "B", 10, 4, 20, 8)
+	h.AssertZOrder(a, b)
+	h.AssertTop(b)
+
+	h.Click(4, 5)
+	h.AssertZOrder(b, a)
+	h.AssertTop(a)
+	h.AssertFocus(a)
+	h.AssertFocus(ain)
+	if bin.HasFocus() {
+		t.Error("B's input field still has focus")
+	}
+
+	h.Click(25, 10)
+	h.AssertTop(b)
+	h.AssertFocus(bin)
+	h.Type("hi")
+	if text := bin.GetText(); text != "hi" {
+		t.Errorf("typed %q, expected %q", text, "hi")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	h := tuixtest.New(t, 30, 10)
+	defer h.Close()
+	win := tuix.NewWindow()
+	win.SetTitle("Hello")
+	win.SetBorder(true).SetRect(2, 1, 20, 6)
+	win.SetClient(tview.NewTextView().SetText("Hello, world!"), true)
+	h.Desktop.AddWindow(win)
+	h.SetFocus(win)
+	h.AssertGolden("hello")
+}
+
+// recorder is a testing.TB which records failures instead of failing.
+type recorder struct {
+	testing.TB
+	failed []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = append(r.failed, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	if tuixtest.Updating() {
+		t.Skip("updating")
+	}
+	dir, err := ioutil.TempDir("", "tuixtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	rec := &recorder{TB: t}
+	h := tuixtest.New(rec, 20, 5)
+	defer h.Close()
+	newWindow(h, "A", 1, 1, 10, 3)
+	h.AssertGolden("new")
+	if len(rec.failed) != 1 || !strings.Contains(rec.failed[0], "-tuixtest.update") {
+		t.Fatalf("missing golden file: %q", rec.failed)
+	}
+
+	os.Setenv("TUIXTEST_UPDATE", "1")
+	rec.failed = nil
+	h.AssertGolden("new")
+	os.Unsetenv("TUIXTEST_UPDATE")
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "new.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != h.Snapshot() {
+		t.Errorf("golden file is not the snapshot:\n%s", data)
+	}
+
+	h.AssertGolden("new")
+	if len(rec.failed) != 0 {
+		t.Errorf("unexpected failures: %q", rec.failed)
+	}
+	h.Drag(3, 1, 5, 1)
+	h.AssertGolden("new")
+	if len(rec.failed) != 1 || !strings.Contains(rec.failed[0], "does not match") {
+		t.Errorf("moved window matches: %q", rec.failed)
+	}
+}
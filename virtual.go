@@ -0,0 +1,434 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// A virtual desktop is larger than the desktop's rect, the view shows part of it.
+// Window rects are in desktop space, which is the screen coordinates when the view
+// is scrolled to the top left, so they don't change as the view scrolls;
+// the desktop translates them when drawing and hit testing, see ScreenToDesktop.
+// Maximized and minimized windows stay in the view, their rects are in screen coordinates.
+
+// SetVirtualSize sets the size of the virtual desktop, which can be larger than
+// the desktop's inner rect; scrollbars are shown when it is.
+// A size of 0, or one smaller than the inner rect, uses the inner rect's size.
+func (d *Desktop) SetVirtualSize(width, height int) *Desktop {
+	d.virtW, d.virtH = width, height
+	d.ScrollTo(d.scrollX, d.scrollY)
+	d.winMgr.DesktopResized(d)
+	return d
+}
+
+// GetVirtualSize gets the size of the virtual desktop.
+func (d *Desktop) GetVirtualSize() (width, height int) {
+	_, _, w, h := d.GetViewRect()
+	if d.virtW > w {
+		w = d.virtW
+	}
+	if d.virtH > h {
+		h = d.virtH
+	}
+	return w, h
+}
+
+// SetMiniMap determines if a small overview of the virtual desktop is shown
+// in the top right corner of the view, clicking it scrolls there.
+func (d *Desktop) SetMiniMap(on bool) *Desktop {
+	d.miniMap = on
+	return d
+}
+
+// SetPanKeys determines if alt+arrow keys pan the virtual desktop even when
+// a window or the desktop client has focus, instead of going to it;
+// otherwise they only pan when nothing else has focus.
+func (d *Desktop) SetPanKeys(on bool) *Desktop {
+	d.panKeys = on
+	return d
+}
+
+// childFocused returns true if a window or the desktop client has focus.
+func (d *Desktop) childFocused() bool {
+	if d.client != nil && d.client.HasFocus() {
+		return true
+	}
+	for _, win := range d.wins {
+		if win.HasFocus() {
+			return true
+		}
+	}
+	return false
+}
+
+// isVirtual returns true if the virtual desktop is larger than the inner rect.
+func (d *Desktop) isVirtual() bool {
+	_, _, w, h := d.GetInnerRect()
+	return d.virtW > w || d.virtH > h
+}
+
+// scrollbars gets which scrollbars are shown.
+func (d *Desktop) scrollbars() (horiz, vert bool) {
+	_, _, w, h := d.GetInnerRect()
+	vert = d.virtH > h
+	if vert {
+		w--
+	}
+	horiz = d.virtW > w
+	if horiz && !vert && d.virtH > h-1 {
+		vert = true
+	}
+	return
+}
+
+// GetViewRect gets the part of the inner rect which shows the virtual desktop,
+// which is the inner rect without the scrollbars.
+// Maximized windows fill this rect.
+func (d *Desktop) GetViewRect() (x, y, width, height int) {
+	x, y, width, height = d.GetInnerRect()
+	horiz, vert := d.scrollbars()
+	if vert {
+		width--
+	}
+	if horiz {
+		height--
+	}
+	return
+}
+
+// GetScroll gets how far the view is scrolled from the top left of the virtual desktop.
+func (d *Desktop) GetScroll() (x, y int) {
+	return d.scrollX, d.scrollY
+}
+
+// ScrollTo scrolls the view to the position from the top left of the virtual desktop,
+// within the virtual desktop. Restored windows move with the desktop.
+func (d *Desktop) ScrollTo(x, y int) *Desktop {
+	_, _, vw, vh := d.GetViewRect()
+	virtW, virtH := d.GetVirtualSize()
+	if x > virtW-vw {
+		x = virtW - vw
+	}
+	if y > virtH-vh {
+		y = virtH - vh
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	d.scrollX, d.scrollY = x, y
+	return d
+}
+
+// ScrollBy scrolls the view by the number of cells.
+func (d *Desktop) ScrollBy(dx, dy int) *Desktop {
+	return d.ScrollTo(d.scrollX+dx, d.scrollY+dy)
+}
+
+// ScrollToWindow scrolls the view the least amount to show as much of the window as fits.
+func (d *Desktop) ScrollToWindow(win *Window) *Desktop {
+	if win.desktop != d || win.state != Restored {
+		return d
+	}
+	vx, vy, vw, vh := d.GetViewRect()
+	x, y, w, h := d.WindowToScreen(win)
+	dx, dy := 0, 0
+	if x+w > vx+vw {
+		dx = x + w - (vx + vw)
+	}
+	if x-dx < vx {
+		dx = x - vx
+	}
+	if y+h > vy+vh {
+		dy = y + h - (vy + vh)
+	}
+	if y-dy < vy {
+		dy = y - vy
+	}
+	return d.ScrollBy(dx, dy)
+}
+
+// ScreenToDesktop converts a screen position to desktop space.
+func (d *Desktop) ScreenToDesktop(x, y int) (int, int) {
+	return x + d.scrollX, y + d.scrollY
+}
+
+// DesktopToScreen converts a desktop space position to the screen.
+func (d *Desktop) DesktopToScreen(x, y int) (int, int) {
+	return x - d.scrollX, y - d.scrollY
+}
+
+// WindowToScreen gets where the window is on the screen.
+func (d *Desktop) WindowToScreen(win *Window) (x, y, width, height int) {
+	x, y, width, height = win.GetRect()
+	dx, dy := d.stateOffset(win.state)
+	return x + dx, y + dy, width, height
+}
+
+// stateOffset gets how far windows in the state are drawn from their rects;
+// restored windows scroll with the view, the others stay in it.
+func (d *Desktop) stateOffset(state WindowState) (dx, dy int) {
+	if state != Restored {
+		return 0, 0
+	}
+	return -d.scrollX, -d.scrollY
+}
+
+// windowScreen gets the screen to draw the window on, in its coordinates,
+// clipped to the view of a virtual desktop.
+func (d *Desktop) windowScreen(screen tcell.Screen, win *Window) tcell.Screen {
+	if !d.isVirtual() {
+		return screen
+	}
+	dx, dy := d.stateOffset(win.state)
+	vx, vy, vw, vh := d.GetViewRect()
+	return offsetScreen(screen, vx-dx, vy-dy, vw, vh, dx, dy)
+}
+
+// windowEvent gets the mouse event in the window's coordinates.
+func (d *Desktop) windowEvent(win *Window, event *tcell.EventMouse) *tcell.EventMouse {
+	dx, dy := d.stateOffset(win.state)
+	if dx == 0 && dy == 0 {
+		return event
+	}
+	x, y := event.Position()
+	return tcell.NewEventMouse(x-dx, y-dy, event.Buttons(), event.Modifiers())
+}
+
+// scrollThumb gets the position and length of a scrollbar thumb,
+// for a scrollbar of length size showing view of total at pos.
+func scrollThumb(size, view, total, pos int) (thumbPos, thumbLen int) {
+	thumbLen = size * view / total
+	if thumbLen < 1 {
+		thumbLen = 1
+	}
+	if total > view {
+		thumbPos = pos * (size - thumbLen) / (total - view)
+	}
+	return
+}
+
+// drawScrollbar draws a scrollbar of length cells at the position, showing view of total at pos.
+func drawScrollbar(screen tcell.Screen, x, y, length int, vertical bool, view, total, pos int, theme *WindowTheme, bg tcell.Color) {
+	track := tcell.StyleDefault.Foreground(theme.InactiveBorderColor).Background(bg)
+	thumb := tcell.StyleDefault.Foreground(theme.ActiveBorderColor).Background(bg)
+	tpos, n := scrollThumb(length, view, total, pos)
+	for i := 0; i < length; i++ {
+		c, style := '░', track
+		if i >= tpos && i < tpos+n {
+			c, style = '█', thumb
+		}
+		if vertical {
+			screen.SetContent(x, y+i, c, nil, style)
+		} else {
+			screen.SetContent(x+i, y, c, nil, style)
+		}
+	}
+}
+
+// drawScrollbars draws the scrollbars of a virtual desktop.
+func (d *Desktop) drawScrollbars(screen tcell.Screen, theme *WindowTheme) {
+	horiz, vert := d.scrollbars()
+	vx, vy, vw, vh := d.GetViewRect()
+	virtW, virtH := d.GetVirtualSize()
+	if horiz {
+		drawScrollbar(screen, vx, vy+vh, vw, false, vw, virtW, d.scrollX, theme, theme.DesktopColor)
+	}
+	if vert {
+		drawScrollbar(screen, vx+vw, vy, vh, true, vh, virtH, d.scrollY, theme, theme.DesktopColor)
+	}
+	if horiz && vert {
+		screen.SetContent(vx+vw, vy+vh, ' ', nil,
+			tcell.StyleDefault.Foreground(theme.InactiveBorderColor).Background(theme.DesktopColor))
+	}
+}
+
+// miniMapRect gets where the mini map is drawn.
+func (d *Desktop) miniMapRect() (x, y, width, height int) {
+	vx, vy, vw, vh := d.GetViewRect()
+	virtW, virtH := d.GetVirtualSize()
+	width = vw / 4
+	if width > 24 {
+		width = 24
+	}
+	height = width * virtH / virtW / 2 // Cells are about twice as tall as wide.
+	if height < 2 {
+		height = 2
+	}
+	if height > vh/3 {
+		height = vh / 3
+	}
+	if width < 4 || height < 2 {
+		return vx + vw, vy, 0, 0
+	}
+	return vx + vw - width, vy, width, height
+}
+
+// drawMiniMap draws an overview of the virtual desktop, with its windows and the view.
+func (d *Desktop) drawMiniMap(screen tcell.Screen, theme *WindowTheme) {
+	mx, my, mw, mh := d.miniMapRect()
+	if mw == 0 {
+		return
+	}
+	virtW, virtH := d.GetVirtualSize()
+	vx, vy, vw, vh := d.GetViewRect()
+	toMap := func(x, y int) (int, int) {
+		x, y = d.ScreenToDesktop(x, y)
+		return mx + (x-vx)*mw/virtW, my + (y-vy)*mh/virtH
+	}
+	mapScreen := ClipScreen(screen, mx, my, mw, mh)
+	bg := tcell.StyleDefault.Foreground(theme.InactiveBorderColor).Background(theme.DesktopColor)
+	for j := my; j < my+mh; j++ {
+		for i := mx; i < mx+mw; i++ {
+			mapScreen.SetContent(i, j, '·', nil, bg)
+		}
+	}
+	for _, win := range d.wins {
+		if win.state == Minimized {
+			continue
+		}
+		x, y, w, h := d.WindowToScreen(win)
+		x1, y1 := toMap(x, y)
+		x2, y2 := toMap(x+w, y+h)
+		style := tcell.StyleDefault.Foreground(theme.InactiveCaptionColor).Background(theme.DesktopColor)
+		if win.HasFocus() {
+			style = style.Foreground(theme.ActiveCaptionColor)
+		}
+		for j := y1; j < y2 || j == y1; j++ {
+			for i := x1; i < x2 || i == x1; i++ {
+				mapScreen.SetContent(i, j, '█', nil, style)
+			}
+		}
+	}
+	x1, y1 := toMap(vx, vy)
+	x2, y2 := toMap(vx+vw, vy+vh)
+	drawOutline(mapScreen, x1, y1, x2-x1, y2-y1,
+		tcell.StyleDefault.Foreground(theme.ActiveBorderColor).Bold(theme.Monochrome))
+}
+
+// virtualMouseHandler handles the scrollbars, mini map and panning of a virtual desktop.
+func (d *Desktop) virtualMouseHandler(action tview.MouseAction, event *tcell.EventMouse) (consumed bool, capture tview.Primitive) {
+	atX, atY := event.Position()
+	vx, vy, vw, vh := d.GetViewRect()
+	virtW, virtH := d.GetVirtualSize()
+
+	if d.scrollDrag != 0 || d.panning {
+		switch action {
+		case tview.MouseMove:
+			if d.scrollDrag == 1 {
+				_, n := scrollThumb(vw, vw, virtW, d.scrollX)
+				if vw > n {
+					d.ScrollTo((atX-vx-d.scrollDragOff)*(virtW-vw)/(vw-n), d.scrollY)
+				}
+			} else if d.scrollDrag == 2 {
+				_, n := scrollThumb(vh, vh, virtH, d.scrollY)
+				if vh > n {
+					d.ScrollTo(d.scrollX, (atY-vy-d.scrollDragOff)*(virtH-vh)/(vh-n))
+				}
+			} else {
+				d.ScrollBy(d.panX-atX, d.panY-atY)
+				d.panX, d.panY = atX, atY
+			}
+			return true, d
+		case tview.MouseLeftUp, tview.MouseMiddleUp:
+			d.scrollDrag = 0
+			d.panning = false
+			return true, nil
+		}
+		return true, d
+	}
+
+	if d.miniMap {
+		mx, my, mw, mh := d.miniMapRect()
+		if mw > 0 && atX >= mx && atX < mx+mw && atY >= my && atY < my+mh {
+			if action == tview.MouseLeftDown {
+				// Center the view on the clicked spot.
+				d.ScrollTo((atX-mx)*virtW/mw-vw/2, (atY-my)*virtH/mh-vh/2)
+			}
+			return true, nil
+		}
+	}
+
+	horiz, vert := d.scrollbars()
+	onHoriz := horiz && atY == vy+vh && atX >= vx && atX < vx+vw
+	onVert := vert && atX == vx+vw && atY >= vy && atY < vy+vh
+	if !onHoriz && !onVert {
+		return false, nil
+	}
+	if action == tview.MouseLeftDown {
+		if onHoriz {
+			pos, n := scrollThumb(vw, vw, virtW, d.scrollX)
+			if i := atX - vx; i < pos {
+				d.ScrollBy(-vw, 0)
+			} else if i >= pos+n {
+				d.ScrollBy(vw, 0)
+			} else {
+				d.scrollDrag, d.scrollDragOff = 1, i-pos
+				return true, d
+			}
+		} else {
+			pos, n := scrollThumb(vh, vh, virtH, d.scrollY)
+			if j := atY - vy; j < pos {
+				d.ScrollBy(0, -vh)
+			} else if j >= pos+n {
+				d.ScrollBy(0, vh)
+			} else {
+				d.scrollDrag, d.scrollDragOff = 2, j-pos
+				return true, d
+			}
+		}
+	}
+	return true, nil
+}
+
+// virtualBackgroundMouse handles mouse events not used by windows or the desktop client:
+// middle drag pans the view, the mouse wheel scrolls it.
+func (d *Desktop) virtualBackgroundMouse(action tview.MouseAction, event *tcell.EventMouse) (consumed bool, capture tview.Primitive) {
+	_, _, vw, vh := d.GetViewRect()
+	switch action {
+	case tview.MouseMiddleDown:
+		d.panning = true
+		d.panX, d.panY = event.Position()
+		return true, d
+	case tview.MouseScrollUp:
+		d.ScrollBy(0, -(vh/8 + 1))
+	case tview.MouseScrollDown:
+		d.ScrollBy(0, vh/8+1)
+	case tview.MouseScrollLeft:
+		d.ScrollBy(-(vw/8 + 1), 0)
+	case tview.MouseScrollRight:
+		d.ScrollBy(vw/8+1, 0)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// virtualKey pans the view with alt+arrow keys, returns true if consumed.
+func (d *Desktop) virtualKey(event *tcell.EventKey) bool {
+	if event.Modifiers()&tcell.ModAlt == 0 {
+		return false
+	}
+	_, _, vw, vh := d.GetViewRect()
+	switch event.Key() {
+	case tcell.KeyLeft:
+		d.ScrollBy(-(vw/4 + 1), 0)
+	case tcell.KeyRight:
+		d.ScrollBy(vw/4+1, 0)
+	case tcell.KeyUp:
+		d.ScrollBy(0, -(vh/4 + 1))
+	case tcell.KeyDown:
+		d.ScrollBy(0, vh/4+1)
+	default:
+		return false
+	}
+	return true
+}
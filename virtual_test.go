@@ -0,0 +1,133 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+	"github.com/rivo/tview"
+)
+
+// resizeCounter is a window manager which counts Resized calls.
+type resizeCounter struct {
+	tuix.WindowManager
+	resized int
+}
+
+func (wm *resizeCounter) Resized(win *tuix.Window) {
+	wm.resized++
+	wm.WindowManager.Resized(win)
+}
+
+func TestVirtualScroll(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	wm := &resizeCounter{WindowManager: tuix.NewWindowManager(tuix.ClassicWindowTheme)}
+	h.Desktop.SetWindowManager(wm)
+	h.Desktop.SetVirtualSize(200, 60)
+	a := textWindow(h, "A", "", 40, 5, 16, 6)
+	b := textWindow(h, "B", "", 2, 2, 16, 6)
+	wm.resized = 0
+
+	h.Desktop.ScrollTo(30, 1)
+	h.Draw()
+	h.AssertRect(a, 40, 5, 16, 6)
+	h.AssertRect(b, 2, 2, 16, 6)
+	if wm.resized != 0 {
+		t.Errorf("scrolling resized windows %d times", wm.resized)
+	}
+	if x, y, _, _ := h.Desktop.WindowToScreen(a); x != 10 || y != 4 {
+		t.Errorf("window A is at (%d, %d) on the screen", x, y)
+	}
+	h.AssertText(10, 4, "┌A─")
+	h.AssertText(11, 10, " ")
+	if win := h.Desktop.WindowAt(11, 4); win != a {
+		t.Errorf("window at (11, 4) is %v, expected A", win)
+	}
+	if win := h.Desktop.WindowAt(3, 2); win != nil {
+		t.Errorf("window B was hit after scrolling it out of the view")
+	}
+
+	h.Click(12, 6)
+	h.AssertTop(a)
+	h.AssertFocus(a)
+	h.Drag(13, 4, 18, 7)
+	h.AssertRect(a, 45, 8, 16, 6)
+	h.AssertText(15, 7, "╔A═")
+
+	h.Wheel(40, 15, 2)
+	sx, sy := h.Desktop.GetScroll()
+	if sx != 30 || sy <= 1 {
+		t.Errorf("wheel on the desktop scrolled to (%d, %d)", sx, sy)
+	}
+	h.AssertRect(a, 45, 8, 16, 6)
+}
+
+func TestVirtualMaximized(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	h.Desktop.SetVirtualSize(200, 60)
+	a := textWindow(h, "A", "", 40, 5, 16, 6)
+	h.Desktop.ScrollTo(30, 10)
+	a.SetState(tuix.Maximized)
+	h.Draw()
+	vx, vy, vw, vh := h.Desktop.GetViewRect()
+	h.AssertRect(a, vx, vy, vw, vh)
+	h.Desktop.ScrollBy(5, 5)
+	h.Draw()
+	h.AssertRect(a, vx, vy, vw, vh)
+	h.AssertText(vx, vy, "┌A")
+
+	a.SetState(tuix.Restored)
+	h.Draw()
+	h.AssertRect(a, 40, 5, 16, 6)
+	if x, y, _, _ := h.Desktop.WindowToScreen(a); x != 5 || y != -10 {
+		t.Errorf("restored window A is at (%d, %d) on the screen", x, y)
+	}
+}
+
+func TestVirtualPanKeys(t *testing.T) {
+	h := tuixtest.New(t, 60, 20)
+	defer h.Close()
+	h.Desktop.SetVirtualSize(200, 60)
+	keys := 0
+	client := tview.NewBox().SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		keys++
+		return event
+	})
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(2, 2, 20, 8)
+	win.SetClient(client, true)
+	h.Desktop.AddWindow(win)
+
+	h.SetFocus(win)
+	h.Key(tcell.KeyRight, 0, tcell.ModAlt)
+	if x, y := h.Desktop.GetScroll(); x != 0 || y != 0 || keys != 1 {
+		t.Errorf("alt+right scrolled to (%d, %d), window got %d keys; expected it to go to the window", x, y, keys)
+	}
+
+	h.Desktop.SetPanKeys(true)
+	h.Key(tcell.KeyDown, 0, tcell.ModAlt)
+	if _, y := h.Desktop.GetScroll(); y == 0 || keys != 1 {
+		t.Errorf("alt+down with pan keys didn't pan, window got %d keys", keys)
+	}
+	h.Key(tcell.KeyDown, 0, tcell.ModNone)
+	if keys != 2 {
+		t.Errorf("down without alt didn't go to the window")
+	}
+
+	h.Desktop.SetPanKeys(false)
+	h.Desktop.RemoveWindow(win)
+	h.SetFocus(h.Desktop)
+	h.Key(tcell.KeyRight, 0, tcell.ModAlt)
+	if x, _ := h.Desktop.GetScroll(); x == 0 {
+		t.Error("alt+right without a focused window didn't pan")
+	}
+}
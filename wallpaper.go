@@ -0,0 +1,225 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix
+
+import (
+	"image"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Wallpaper draws a desktop background; see WindowTheme.Wallpaper.
+// The desktop background color is drawn first, so a wallpaper can leave cells alone.
+type Wallpaper interface {
+	DrawWallpaper(screen tcell.Screen, x, y, width, height int)
+}
+
+// WallpaperFunc is a func implementing Wallpaper.
+type WallpaperFunc func(screen tcell.Screen, x, y, width, height int)
+
+func (f WallpaperFunc) DrawWallpaper(screen tcell.Screen, x, y, width, height int) {
+	f(screen, x, y, width, height)
+}
+
+// wallpaperStyle gets a style for the colors, keeping the existing background
+// of the cell if bg is ColorDefault.
+func wallpaperStyle(screen tcell.Screen, x, y int, fg, bg tcell.Color) tcell.Style {
+	style := tcell.StyleDefault.Foreground(fg)
+	if bg == tcell.ColorDefault {
+		_, _, oldStyle, _ := screen.GetContent(x, y)
+		_, bg, _ = oldStyle.Decompose()
+	}
+	return style.Background(bg)
+}
+
+// PatternWallpaper tiles a pattern of runes over the desktop.
+// The pattern can have multiple lines; all runes are assumed to be single width.
+// If bg is ColorDefault, the desktop background color is kept.
+func PatternWallpaper(pattern string, fg, bg tcell.Color) Wallpaper {
+	var lines [][]rune
+	for _, line := range strings.Split(pattern, "\n") {
+		if line != "" {
+			lines = append(lines, []rune(line))
+		}
+	}
+	return WallpaperFunc(func(screen tcell.Screen, x, y, width, height int) {
+		if len(lines) == 0 {
+			return
+		}
+		for j := 0; j < height; j++ {
+			line := lines[j%len(lines)]
+			for i := 0; i < width; i++ {
+				screen.SetContent(x+i, y+j, line[i%len(line)], nil,
+					wallpaperStyle(screen, x+i, y+j, fg, bg))
+			}
+		}
+	})
+}
+
+// GradientWallpaper fills the desktop with a gradient between two colors,
+// top to bottom if vertical, otherwise left to right.
+// The colors are blended as RGB; tcell picks the closest color when the
+// terminal does not support true color.
+// If either color has no RGB value, such as ColorDefault, the other is filled solid;
+// if neither does, the desktop background color is kept.
+func GradientWallpaper(from, to tcell.Color, vertical bool) Wallpaper {
+	r1, g1, b1 := from.RGB()
+	r2, g2, b2 := to.RGB()
+	if r1 < 0 || r2 < 0 {
+		solid := from
+		if r1 < 0 {
+			solid = to
+		}
+		return WallpaperFunc(func(screen tcell.Screen, x, y, width, height int) {
+			if r, _, _ := solid.RGB(); r < 0 {
+				return
+			}
+			for j := 0; j < height; j++ {
+				for i := 0; i < width; i++ {
+					screen.SetContent(x+i, y+j, ' ', nil, tcell.StyleDefault.Background(solid))
+				}
+			}
+		})
+	}
+	return WallpaperFunc(func(screen tcell.Screen, x, y, width, height int) {
+		steps := width
+		if vertical {
+			steps = height
+		}
+		for j := 0; j < height; j++ {
+			for i := 0; i < width; i++ {
+				n := i
+				if vertical {
+					n = j
+				}
+				var color tcell.Color
+				if steps <= 1 {
+					color = from
+				} else {
+					s := int32(steps - 1)
+					color = tcell.NewRGBColor(
+						r1+(r2-r1)*int32(n)/s,
+						g1+(g2-g1)*int32(n)/s,
+						b1+(b2-b1)*int32(n)/s)
+				}
+				screen.SetContent(x+i, y+j, ' ', nil, tcell.StyleDefault.Background(color))
+			}
+		}
+	})
+}
+
+// ArtWallpaper draws multi-line text art centered on the desktop.
+// Spaces in the art are not drawn; all runes are assumed to be single width.
+// If bg is ColorDefault, the desktop background color is kept.
+func ArtWallpaper(art string, fg, bg tcell.Color) Wallpaper {
+	var lines [][]rune
+	artWidth := 0
+	for _, line := range strings.Split(strings.TrimRight(art, "\n"), "\n") {
+		rline := []rune(strings.TrimRight(line, " \r"))
+		if len(rline) > artWidth {
+			artWidth = len(rline)
+		}
+		lines = append(lines, rline)
+	}
+	return WallpaperFunc(func(screen tcell.Screen, x, y, width, height int) {
+		artX := x + (width-artWidth)/2
+		artY := y + (height-len(lines))/2
+		for j, line := range lines {
+			if artY+j < y || artY+j >= y+height {
+				continue
+			}
+			for i, c := range line {
+				if c == ' ' || artX+i < x || artX+i >= x+width {
+					continue
+				}
+				screen.SetContent(artX+i, artY+j, c, nil,
+					wallpaperStyle(screen, artX+i, artY+j, fg, bg))
+			}
+		}
+	})
+}
+
+// LoadArtWallpaper loads text art from a file, see ArtWallpaper.
+func LoadArtWallpaper(filename string, fg, bg tcell.Color) (Wallpaper, error) {
+	art, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ArtWallpaper(string(art), fg, bg), nil
+}
+
+// ImageWallpaper draws an image scaled to fit and centered on the desktop.
+// Each cell shows two pixels using the upper half block,
+// so the image keeps roughly its aspect ratio.
+// Mostly transparent pixels are not drawn.
+func ImageWallpaper(img image.Image) Wallpaper {
+	return &imageWallpaper{img: img}
+}
+
+type imageWallpaper struct {
+	img           image.Image
+	width, height int
+	colors        []tcell.Color // Scaled pixels, width*height*2, ColorDefault if transparent.
+}
+
+// scale resizes the image to fit in width by height*2 pixels, if not already.
+func (iw *imageWallpaper) scale(width, height int) {
+	if iw.colors != nil && iw.width == width && iw.height == height {
+		return
+	}
+	iw.width, iw.height = width, height
+	iw.colors = make([]tcell.Color, width*height*2)
+	bounds := iw.img.Bounds()
+	imgW, imgH := bounds.Dx(), bounds.Dy()
+	if imgW <= 0 || imgH <= 0 || width <= 0 || height <= 0 {
+		return
+	}
+	// Fit the image, keeping its aspect ratio.
+	scaledW, scaledH := width, imgH*width/imgW
+	if scaledH > height*2 {
+		scaledW, scaledH = imgW*height*2/imgH, height*2
+	}
+	offX, offY := (width-scaledW)/2, (height*2-scaledH)/2
+	for py := 0; py < height*2; py++ {
+		for px := 0; px < width; px++ {
+			color := tcell.ColorDefault
+			sx, sy := px-offX, py-offY
+			if sx >= 0 && sy >= 0 && sx < scaledW && sy < scaledH {
+				r, g, b, a := iw.img.At(bounds.Min.X+sx*imgW/scaledW, bounds.Min.Y+sy*imgH/scaledH).RGBA()
+				if a >= 0x8000 {
+					color = tcell.NewRGBColor(int32(r>>8), int32(g>>8), int32(b>>8))
+				}
+			}
+			iw.colors[py*width+px] = color
+		}
+	}
+}
+
+func (iw *imageWallpaper) DrawWallpaper(screen tcell.Screen, x, y, width, height int) {
+	iw.scale(width, height)
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			top := iw.colors[j*2*width+i]
+			bottom := iw.colors[(j*2+1)*width+i]
+			if top == tcell.ColorDefault && bottom == tcell.ColorDefault {
+				continue
+			}
+			_, _, style, _ := screen.GetContent(x+i, y+j)
+			_, bg, _ := style.Decompose()
+			if top == tcell.ColorDefault {
+				top = bg
+			}
+			if bottom == tcell.ColorDefault {
+				bottom = bg
+			}
+			screen.SetContent(x+i, y+j, '▀', nil,
+				tcell.StyleDefault.Foreground(top).Background(bottom))
+		}
+	}
+}
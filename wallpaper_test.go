@@ -0,0 +1,139 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+)
+
+// desktopScreen is a screen filled with the navy desktop background.
+func desktopScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	screen := simScreen(t, width, height)
+	screen.Fill(' ', tcell.StyleDefault.Background(tcell.ColorNavy))
+	return screen
+}
+
+func assertCell(t *testing.T, screen tcell.Screen, x, y int, c rune, style tcell.Style) {
+	t.Helper()
+	if gotc, _, gotStyle, _ := screen.GetContent(x, y); gotc != c || gotStyle != style {
+		t.Errorf("cell (%d, %d) is %q %v, expected %q %v", x, y, gotc, gotStyle, c, style)
+	}
+}
+
+func TestPatternWallpaper(t *testing.T) {
+	screen := desktopScreen(t, 6, 5)
+	defer screen.Fini()
+	tuix.PatternWallpaper("ab\ncd\n", tcell.ColorYellow, tcell.ColorDefault).DrawWallpaper(screen, 1, 1, 3, 3)
+	style := tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorNavy)
+	assertCell(t, screen, 1, 1, 'a', style)
+	assertCell(t, screen, 3, 1, 'a', style)
+	assertCell(t, screen, 2, 2, 'd', style)
+	assertCell(t, screen, 1, 3, 'a', style)
+	assertCell(t, screen, 4, 1, ' ', tcell.StyleDefault.Background(tcell.ColorNavy))
+
+	tuix.PatternWallpaper("x", tcell.ColorYellow, tcell.ColorRed).DrawWallpaper(screen, 0, 0, 6, 5)
+	assertCell(t, screen, 5, 4, 'x', tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorRed))
+}
+
+func TestGradientWallpaper(t *testing.T) {
+	screen := desktopScreen(t, 4, 3)
+	defer screen.Fini()
+	tuix.GradientWallpaper(tcell.NewRGBColor(0, 0, 0), tcell.NewRGBColor(0, 90, 30), false).DrawWallpaper(screen, 0, 0, 4, 3)
+	for i := 0; i < 4; i++ {
+		want := tcell.StyleDefault.Background(tcell.NewRGBColor(0, int32(i*30), int32(i*10)))
+		assertCell(t, screen, i, 2, ' ', want)
+	}
+
+	tuix.GradientWallpaper(tcell.NewRGBColor(0, 0, 0), tcell.NewRGBColor(60, 0, 0), true).DrawWallpaper(screen, 0, 0, 4, 3)
+	assertCell(t, screen, 3, 0, ' ', tcell.StyleDefault.Background(tcell.NewRGBColor(0, 0, 0)))
+	assertCell(t, screen, 0, 1, ' ', tcell.StyleDefault.Background(tcell.NewRGBColor(30, 0, 0)))
+	assertCell(t, screen, 0, 2, ' ', tcell.StyleDefault.Background(tcell.NewRGBColor(60, 0, 0)))
+}
+
+func TestGradientWallpaperDefault(t *testing.T) {
+	screen := desktopScreen(t, 4, 3)
+	defer screen.Fini()
+	tuix.GradientWallpaper(tcell.ColorDefault, tcell.ColorReset, false).DrawWallpaper(screen, 0, 0, 4, 3)
+	assertCell(t, screen, 3, 2, ' ', tcell.StyleDefault.Background(tcell.ColorNavy))
+
+	tuix.GradientWallpaper(tcell.ColorDefault, tcell.ColorRed, false).DrawWallpaper(screen, 0, 0, 4, 3)
+	for i := 0; i < 4; i++ {
+		assertCell(t, screen, i, 0, ' ', tcell.StyleDefault.Background(tcell.ColorRed))
+	}
+	tuix.GradientWallpaper(tcell.ColorGreen, tcell.ColorReset, true).DrawWallpaper(screen, 0, 0, 4, 3)
+	assertCell(t, screen, 0, 2, ' ', tcell.StyleDefault.Background(tcell.ColorGreen))
+}
+
+func TestArtWallpaper(t *testing.T) {
+	screen := desktopScreen(t, 7, 4)
+	defer screen.Fini()
+	tuix.ArtWallpaper("x y\n z\n", tcell.ColorYellow, tcell.ColorDefault).DrawWallpaper(screen, 0, 0, 7, 4)
+	style := tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorNavy)
+	assertCell(t, screen, 2, 1, 'x', style)
+	assertCell(t, screen, 3, 1, ' ', tcell.StyleDefault.Background(tcell.ColorNavy)) // Spaces aren't drawn.
+	assertCell(t, screen, 4, 1, 'y', style)
+	assertCell(t, screen, 3, 2, 'z', style)
+
+	// Art bigger than the desktop is clipped.
+	screen.Fill(' ', tcell.StyleDefault)
+	tuix.ArtWallpaper("abcde\nfghij\nklmno", tcell.ColorYellow, tcell.ColorRed).DrawWallpaper(screen, 1, 1, 3, 1)
+	assertCell(t, screen, 1, 1, 'g', tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorRed))
+	assertCell(t, screen, 3, 1, 'i', tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorRed))
+	for _, pos := range [][2]int{{0, 1}, {4, 1}, {2, 0}, {2, 2}} {
+		assertCell(t, screen, pos[0], pos[1], ' ', tcell.StyleDefault)
+	}
+}
+
+func TestLoadArtWallpaper(t *testing.T) {
+	if _, err := tuix.LoadArtWallpaper("testdata/no-such-art.txt", tcell.ColorYellow, tcell.ColorDefault); err == nil {
+		t.Error("loaded art from a missing file")
+	}
+	f, err := ioutil.TempFile("", "art")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("*\n")
+	f.Close()
+	wallpaper, err := tuix.LoadArtWallpaper(f.Name(), tcell.ColorYellow, tcell.ColorDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen := desktopScreen(t, 3, 3)
+	defer screen.Fini()
+	wallpaper.DrawWallpaper(screen, 0, 0, 3, 3)
+	assertCell(t, screen, 1, 1, '*', tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorNavy))
+}
+
+func TestImageWallpaper(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{0, 255, 0, 255})
+	img.Set(0, 1, color.RGBA{0, 0, 255, 255})
+	// (1, 1) is transparent.
+	screen := desktopScreen(t, 6, 3)
+	defer screen.Fini()
+	wallpaper := tuix.ImageWallpaper(img)
+	wallpaper.DrawWallpaper(screen, 2, 1, 2, 1)
+	red, green, blue := tcell.NewRGBColor(255, 0, 0), tcell.NewRGBColor(0, 255, 0), tcell.NewRGBColor(0, 0, 255)
+	assertCell(t, screen, 2, 1, '▀', tcell.StyleDefault.Foreground(red).Background(blue))
+	assertCell(t, screen, 3, 1, '▀', tcell.StyleDefault.Foreground(green).Background(tcell.ColorNavy))
+
+	// Scaled to fit, each pixel is 3x3 of the 6x3 cells of two pixels each.
+	screen.Fill(' ', tcell.StyleDefault.Background(tcell.ColorNavy))
+	wallpaper.DrawWallpaper(screen, 0, 0, 6, 3)
+	assertCell(t, screen, 0, 0, '▀', tcell.StyleDefault.Foreground(red).Background(red))
+	assertCell(t, screen, 5, 1, '▀', tcell.StyleDefault.Foreground(green).Background(tcell.ColorNavy))
+	assertCell(t, screen, 5, 2, ' ', tcell.StyleDefault.Background(tcell.ColorNavy))
+}
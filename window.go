@@ -20,6 +20,7 @@ type Window struct {
 	moveX, moveY   int
 	rx, ry, rw, rh int // Restored rect.
 	state          WindowState
+	prevState      WindowState // Before the last SetState.
 	clientFullSize bool
 	border         bool
 	noCaption      bool
@@ -27,7 +28,16 @@ type Window struct {
 	moving         bool
 	autoPosition   bool
 	resizable      bool
+	closable       bool
 	resizing       byte // 1=horiz, 2=vert, 3=both
+	outlining      bool // Moving or resizing with an outline, see Desktop.SetOutlineDrag.
+	outlineX       int
+	outlineY       int
+	outlineW       int
+	outlineH       int
+	mouseEnter     func()
+	mouseLeave     func()
+	closeFunc      func()
 }
 
 func NewWindow() *Window {
@@ -35,9 +45,21 @@ func NewWindow() *Window {
 		Box:          tview.NewBox(),
 		autoActivate: true,
 	}
+	// The theme's colors are used where these are ColorDefault.
+	win.Box.SetBackgroundColor(tcell.ColorDefault)
+	win.Box.SetBorderColor(tcell.ColorDefault)
+	win.Box.SetTitleColor(tcell.ColorDefault)
 	return win
 }
 
+// backgroundColor gets the window's own background color, or the theme's.
+func (win *Window) backgroundColor(theme *WindowTheme) tcell.Color {
+	if color := win.GetBackgroundColor(); color != tcell.ColorDefault && !theme.Monochrome {
+		return color
+	}
+	return theme.ClientBackgroundColor
+}
+
 // GetClient gets the client primitive previously set by SetClient, or nil.
 func (win *Window) GetClient() tview.Primitive {
 	return win.client
@@ -80,6 +102,47 @@ func (win *Window) SetResizable(on bool) *Window {
 	return win
 }
 
+// SetClosable determines if the window shows a close button.
+func (win *Window) SetClosable(on bool) *Window {
+	win.closable = on
+	return win
+}
+
+// SetMouseEnterFunc sets a func called when the mouse pointer moves onto the window.
+func (win *Window) SetMouseEnterFunc(f func()) *Window {
+	win.mouseEnter = f
+	return win
+}
+
+// SetMouseLeaveFunc sets a func called when the mouse pointer moves off of the window.
+func (win *Window) SetMouseLeaveFunc(f func()) *Window {
+	win.mouseLeave = f
+	return win
+}
+
+// SetCloseFunc sets a func called when the window is closed, such as by its close button;
+// use it to release what the client holds, such as a terminal's program.
+func (win *Window) SetCloseFunc(f func()) *Window {
+	win.closeFunc = f
+	return win
+}
+
+// IsHovered returns true if the mouse pointer is over the window,
+// and not over a window in front of it.
+func (win *Window) IsHovered() bool {
+	return win.desktop != nil && win.desktop.hover == win
+}
+
+// Close removes the window from its desktop, and calls the close func.
+func (win *Window) Close() {
+	if win.desktop != nil {
+		win.desktop.RemoveWindow(win)
+	}
+	if win.closeFunc != nil {
+		win.closeFunc()
+	}
+}
+
 // InitWindow is called by the Desktop to initialize the window.
 // Do not call directly!
 func (win *Window) InitWindow() {
@@ -87,12 +150,12 @@ func (win *Window) InitWindow() {
 	if d == nil {
 		return
 	}
-	theme := d.winMgr.GetTheme()
-	win.SetTitleAlign(theme.TitleAlign)
+	win.SetTitleAlign(d.winMgr.GetTheme().TitleAlign)
 	if win.autoPosition {
 		inX, inY, inW, inH := d.GetInnerRect()
 		_, _, winW, winH := win.GetRect()
-		win.SetRect(inX+d.autoWinPos, inY+d.autoWinPos, winW, winH)
+		x, y := d.ScreenToDesktop(inX+d.autoWinPos, inY+d.autoWinPos) // In the view.
+		win.SetRect(x, y, winW, winH)
 		d.autoWinPos += 2
 		if d.autoWinPos >= inW-10 || d.autoWinPos >= inH-10 {
 			// When there's not much screen space left, reset...
@@ -104,8 +167,9 @@ func (win *Window) InitWindow() {
 	}
 }
 
-func (win *Window) SetRect(x, y, width, height int) {
-	if win.border { // Same code in Set*Rect.
+// boundSize limits the size of the window to what the border needs.
+func (win *Window) boundSize(width, height int) (int, int) {
+	if win.border {
 		if width < 12 {
 			width = 12
 		}
@@ -113,6 +177,11 @@ func (win *Window) SetRect(x, y, width, height int) {
 			height = 2
 		}
 	}
+	return width, height
+}
+
+func (win *Window) SetRect(x, y, width, height int) {
+	width, height = win.boundSize(width, height)
 	win.Box.SetRect(x, y, width, height)
 	if win.state == Restored {
 		win.rx, win.ry, win.rw, win.rh = win.GetRect()
@@ -136,14 +205,7 @@ func (win *Window) SetRestoredRect(x, y, width, height int) {
 	if win.state == Restored {
 		win.SetRect(x, y, width, height)
 	} else {
-		if win.border { // Same code in Set*Rect.
-			if width < 12 {
-				width = 12
-			}
-			if height < 2 {
-				height = 2
-			}
-		}
+		width, height = win.boundSize(width, height)
 		win.rx, win.ry, win.rw, win.rh = x, y, width, height
 	}
 }
@@ -153,7 +215,7 @@ func (win *Window) GetState() WindowState {
 }
 
 func (win *Window) SetState(state WindowState) *Window {
-	win.state = state
+	win.prevState, win.state = win.state, state
 	if win.desktop != nil {
 		win.desktop.winMgr.StateChanged(win)
 	}
@@ -0,0 +1,30 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/millerlogic/tuix/tuixtest"
+)
+
+func TestWindowCloseFunc(t *testing.T) {
+	h := tuixtest.New(t, 40, 12)
+	defer h.Close()
+	a := textWindow(h, "A", "", 2, 2, 20, 8)
+	b := textWindow(h, "B", "", 4, 3, 20, 8)
+	closed := 0
+	a.SetClosable(true).SetCloseFunc(func() { closed++ })
+	h.SetFocus(a)
+	h.AssertText(20, 2, "×")
+	h.Click(20, 2)
+	if closed != 1 {
+		t.Errorf("close func called %d times", closed)
+	}
+	h.AssertZOrder(b)
+	h.AssertFocus(b)
+}
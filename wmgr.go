@@ -48,6 +48,7 @@ func (wm *winMgr) TitleChanged(win *Window) {
 }
 
 func (wm *winMgr) StateChanged(win *Window) {
+	x, y, w, h := win.GetRect()
 	switch win.state {
 	case Restored:
 		win.SetRect(win.rx, win.ry, win.rw, win.rh)
@@ -55,9 +56,13 @@ func (wm *winMgr) StateChanged(win *Window) {
 		win.SetRect(0, 0, 1, 1) // Let SetRect bound it.
 	case Maximized:
 		if win.desktop != nil {
-			win.SetRect(win.desktop.GetInnerRect())
+			win.SetRect(win.desktop.GetViewRect())
 		}
 	}
+	if d := win.desktop; d != nil {
+		dx, dy := d.stateOffset(win.prevState)
+		d.AnimateWindow(win, x+dx, y+dy, w, h)
+	}
 }
 
 func (wm *winMgr) GetTheme() WindowTheme {
@@ -65,48 +70,294 @@ func (wm *winMgr) GetTheme() WindowTheme {
 }
 
 func (wm *winMgr) SetTheme(theme WindowTheme) {
-	wm.theme = theme
+	wm.theme = theme.ownFallbacks()
 }
 
 func (wm *winMgr) DesktopResized(d *Desktop) {
 	for _, win := range d.wins {
 		if win.state == Maximized {
-			win.SetRect(d.GetInnerRect())
+			win.SetRect(d.GetViewRect())
 		}
 	}
 }
 
+// screenTheme gets the theme variant for the colors of the desktop's screen.
+func (wm *winMgr) screenTheme(d *Desktop) WindowTheme {
+	if d == nil {
+		return wm.theme
+	}
+	return wm.theme.ForColors(d.colors)
+}
+
 func (wm *winMgr) DesktopDraw(d *Desktop, screen tcell.Screen) {
+	theme := wm.screenTheme(d)
+	x, y, w, h := d.GetInnerRect()
+	color := d.GetBackgroundColor()
+	if color == tcell.ColorDefault || theme.Monochrome {
+		color = theme.DesktopColor
+	}
+	if color != tcell.ColorDefault {
+		fillRect(screen, x, y, w, h, tcell.StyleDefault.Background(color))
+	}
+	if theme.Wallpaper != nil {
+		theme.Wallpaper.DrawWallpaper(screen, x, y, w, h)
+	}
 }
 
 func (wm *winMgr) DefaultDraw(win *Window, screen tcell.Screen) {
+	theme := wm.screenTheme(win.desktop)
+	focused := win.HasFocus()
+	bg := win.backgroundColor(&theme)
+	borderStyle := tcell.StyleDefault.Background(bg)
+	if color := win.GetBorderColor(); color != tcell.ColorDefault {
+		borderStyle = borderStyle.Foreground(color)
+	} else if focused {
+		borderStyle = borderStyle.Foreground(theme.ActiveBorderColor).Bold(theme.Monochrome)
+	} else {
+		borderStyle = borderStyle.Foreground(theme.InactiveBorderColor)
+	}
+	if hasShadow(win, &theme) {
+		wm.drawShadow(win, screen, &theme)
+	}
+	x, y, w, h := win.GetRect()
+	if bg != tcell.ColorDefault {
+		fillRect(screen, x, y, w, h, tcell.StyleDefault.Background(bg))
+	}
 	//win.Box.Draw(screen)
 	win.Box.DrawForSubclass(screen, win)
-	x, y, w, h := win.GetRect()
-	focused := win.HasFocus()
+	if win.border && w >= 2 && h >= 2 {
+		borders := theme.Border
+		if focused && theme.ActiveBorder != (BorderSet{}) {
+			borders = theme.ActiveBorder
+		}
+		if borders == (BorderSet{}) {
+			borders = tviewBorders(focused)
+		}
+		drawBorder(screen, x, y, w, h, borders, tviewBorders(focused).Horizontal, borderStyle)
+		if win.noCaption {
+			// The title is drawn like the border, unless it has its own color.
+			borderColor, _, attrs := borderStyle.Decompose()
+			for i := x + 1; i < x+w-1; i++ {
+				c, combc, style, _ := screen.GetContent(i, y)
+				if fg, _, _ := style.Decompose(); fg == tcell.ColorDefault {
+					screen.SetContent(i, y, c, combc, style.Foreground(borderColor).Attributes(attrs))
+				}
+			}
+		}
+		if owner, ok := win.client.(BorderOwner); ok {
+			owner.DrawBorder(win, screen)
+		}
+	}
 	if !win.noCaption {
 		style := tcell.StyleDefault
-		if focused {
-			style = style.Foreground(wm.theme.ActiveCaptionTextColor)
-			style = style.Background(wm.theme.ActiveCaptionColor)
+		if theme.Monochrome {
+			style = style.Reverse(focused).Bold(focused)
+		} else if focused {
+			style = style.Foreground(theme.ActiveCaptionTextColor)
+			style = style.Background(theme.ActiveCaptionColor)
 		} else {
-			style = style.Foreground(wm.theme.InactiveCaptionTextColor)
-			style = style.Background(wm.theme.InactiveCaptionColor)
+			style = style.Foreground(theme.InactiveCaptionTextColor)
+			style = style.Background(theme.InactiveCaptionColor)
 		}
 		for i := x; i < x+w; i++ {
 			// Use whatever is there as the caption text.
 			c, combc, _, _ := screen.GetContent(i, y)
 			screen.SetContent(i, y, c, combc, style)
 		}
+		if win.border {
+			for _, btn := range wm.captionButtons(win, &theme) {
+				for i, c := range btn.glyph {
+					screen.SetContent(btn.x+i, y, c, nil, style)
+				}
+			}
+		}
 	}
 	if win.resizable && focused && screen.HasMouse() {
 		c, combc, _, _ := screen.GetContent(x+w-1, y+h-1)
 		screen.SetContent(x+w-1, y+h-1, c, combc,
-			tcell.StyleDefault.Foreground(tcell.ColorValid+226))
+			tcell.StyleDefault.Foreground(theme.GripColor).Reverse(theme.Monochrome))
 	}
 	if win.client != nil {
-		win.client.Draw(screen)
+		inX, inY, inW, inH := win.GetInnerRect()
+		win.client.Draw(ClipScreen(screen, inX, inY, inW, inH))
+		if !focused && theme.InactiveDim > 0 {
+			dimRect(screen, theme.InactiveDim, inX, inY, inW, inH)
+		}
+	}
+}
+
+// fillRect fills the rect with spaces in the style.
+func fillRect(screen tcell.Screen, x, y, w, h int, style tcell.Style) {
+	for j := y; j < y+h; j++ {
+		for i := x; i < x+w; i++ {
+			screen.SetContent(i, j, ' ', nil, style)
+		}
+	}
+}
+
+// dimRect dims the cells already drawn in the rect.
+func dimRect(screen tcell.Screen, amount float64, x, y, w, h int) {
+	for j := y; j < y+h; j++ {
+		for i := x; i < x+w; i++ {
+			c, combc, style, _ := screen.GetContent(i, j)
+			screen.SetContent(i, j, c, combc, dimStyle(style, amount, tcell.ColorDefault))
+		}
+	}
+}
+
+// hasShadow returns true if the theme draws a shadow for the window, see drawShadow.
+func hasShadow(win *Window, theme *WindowTheme) bool {
+	return theme.Shadow && !theme.Monochrome && win.state != Maximized
+}
+
+// drawShadow draws the shadow one cell right and below the window,
+// by dimming what was already drawn there.
+func (wm *winMgr) drawShadow(win *Window, screen tcell.Screen, theme *WindowTheme) {
+	x, y, w, h := win.GetRect()
+	dim := theme.ShadowDim
+	if dim <= 0 || dim > 1 {
+		dim = 0.5
+	}
+	shade := func(i, j int) {
+		c, combc, style, _ := screen.GetContent(i, j)
+		screen.SetContent(i, j, c, combc, dimStyle(style, dim, theme.ShadowColor))
+	}
+	for j := y + 1; j <= y+h; j++ {
+		shade(x+w, j)
+	}
+	for i := x + 1; i < x+w; i++ {
+		shade(i, y+h)
+	}
+}
+
+// dimStyle darkens the colors of the style by the amount, from 0 to 1.
+// A default background becomes defaultBg, which is usually dark.
+func dimStyle(style tcell.Style, amount float64, defaultBg tcell.Color) tcell.Style {
+	fg, bg, attr := style.Decompose()
+	if bg == tcell.ColorDefault {
+		bg = defaultBg
+	} else {
+		bg = dimColor(bg, amount)
+	}
+	if fg == tcell.ColorDefault {
+		attr |= tcell.AttrDim
+	} else {
+		fg = dimColor(fg, amount)
+	}
+	return tcell.StyleDefault.Foreground(fg).Background(bg).Attributes(attr)
+}
+
+func dimColor(color tcell.Color, amount float64) tcell.Color {
+	r, g, b := color.RGB()
+	if r < 0 {
+		return color // Not a valid color.
+	}
+	keep := 1 - amount
+	return tcell.NewRGBColor(int32(float64(r)*keep), int32(float64(g)*keep), int32(float64(b)*keep))
+}
+
+// tviewBorders gets the tview border runes, which depend on focus.
+func tviewBorders(focused bool) BorderSet {
+	if focused {
+		return BorderSet{tview.Borders.HorizontalFocus, tview.Borders.VerticalFocus,
+			tview.Borders.TopLeftFocus, tview.Borders.TopRightFocus,
+			tview.Borders.BottomLeftFocus, tview.Borders.BottomRightFocus}
+	}
+	return BorderSet{tview.Borders.Horizontal, tview.Borders.Vertical,
+		tview.Borders.TopLeft, tview.Borders.TopRight,
+		tview.Borders.BottomLeft, tview.Borders.BottomRight}
+}
+
+// drawBorder draws over a border already drawn by tview.
+// Cells on the top line which are not topRune are kept, they're the title.
+func drawBorder(screen tcell.Screen, x, y, w, h int, borders BorderSet, topRune rune, style tcell.Style) {
+	for i := x + 1; i < x+w-1; i++ {
+		if c, _, _, _ := screen.GetContent(i, y); c == topRune {
+			screen.SetContent(i, y, borders.Horizontal, nil, style)
+		}
+		screen.SetContent(i, y+h-1, borders.Horizontal, nil, style)
+	}
+	for j := y + 1; j < y+h-1; j++ {
+		screen.SetContent(x, j, borders.Vertical, nil, style)
+		screen.SetContent(x+w-1, j, borders.Vertical, nil, style)
+	}
+	screen.SetContent(x, y, borders.TopLeft, nil, style)
+	screen.SetContent(x+w-1, y, borders.TopRight, nil, style)
+	screen.SetContent(x, y+h-1, borders.BottomLeft, nil, style)
+	screen.SetContent(x+w-1, y+h-1, borders.BottomRight, nil, style)
+}
+
+type captionButton struct {
+	x     int
+	glyph []rune
+	click func(win *Window, setFocus func(p tview.Primitive))
+}
+
+// captionButtons gets the buttons shown in the window caption, right to left.
+func (wm *winMgr) captionButtons(win *Window, theme *WindowTheme) []captionButton {
+	var btns []captionButton
+	add := func(glyph string, click func(win *Window, setFocus func(p tview.Primitive))) {
+		if glyph != "" {
+			btns = append(btns, captionButton{glyph: []rune(glyph), click: click})
+		}
+	}
+	if win.closable {
+		add(theme.CloseButton, func(win *Window, setFocus func(p tview.Primitive)) {
+			d := win.desktop
+			hadFocus := win.HasFocus()
+			win.Close()
+			if hadFocus && d != nil {
+				if top := d.TopWindow(); top != nil {
+					top.Activate(setFocus)
+				} else {
+					setFocus(d)
+				}
+			}
+		})
+	}
+	if win.resizable {
+		if win.state == Restored {
+			add(theme.MaximizeButton, func(win *Window, setFocus func(p tview.Primitive)) {
+				win.SetState(Maximized)
+			})
+		} else {
+			add(theme.RestoreButton, func(win *Window, setFocus func(p tview.Primitive)) {
+				win.SetState(Restored)
+			})
+		}
+		if win.state != Minimized {
+			add(theme.MinimizeButton, func(win *Window, setFocus func(p tview.Primitive)) {
+				win.SetState(Minimized)
+			})
+		}
+	}
+	x, _, w, _ := win.GetRect()
+	bx := x + w - 1 // Right corner.
+	for i := range btns {
+		bx -= len(btns[i].glyph)
+		if bx <= x+1 {
+			return btns[:i] // Keep the left corner and some of the title on narrow windows.
+		}
+		btns[i].x = bx
+		bx-- // Gap.
 	}
+	return btns
+}
+
+// captionButtonAt gets the caption button at the position, or nil.
+func (wm *winMgr) captionButtonAt(win *Window, atX, atY int) *captionButton {
+	_, y, _, _ := win.GetRect()
+	if !win.border || win.noCaption || atY != y {
+		return nil
+	}
+	theme := wm.screenTheme(win.desktop)
+	btns := wm.captionButtons(win, &theme)
+	for i := range btns {
+		if atX >= btns[i].x && atX < btns[i].x+len(btns[i].glyph) {
+			return &btns[i]
+		}
+	}
+	return nil
 }
 
 func (wm *winMgr) DefaultInputHandler(win *Window, event *tcell.EventKey, setFocus func(p tview.Primitive)) (consumed bool) {
@@ -114,11 +365,27 @@ func (wm *winMgr) DefaultInputHandler(win *Window, event *tcell.EventKey, setFoc
 }
 
 func (wm *winMgr) DefaultMouseHandler(win *Window, action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	if owner, ok := win.client.(BorderOwner); ok && win.border && !win.moving && win.resizing == 0 {
+		if consumed, capture = owner.BorderMouseHandler(win, action, event, setFocus); consumed {
+			return
+		}
+	}
+
 	if !win.InRect(event.Position()) && !win.moving && win.resizing == 0 {
 		return
 	}
 
-	if action == tview.MouseLeftDown {
+	if action == tview.MouseLeftDown || action == tview.MouseLeftClick || action == tview.MouseLeftDoubleClick {
+		atX, atY := event.Position()
+		if btn := wm.captionButtonAt(win, atX, atY); btn != nil {
+			if action != tview.MouseLeftDown && win.desktop.mayMove(win) {
+				btn.click(win, setFocus)
+			}
+			return true, nil
+		}
+	}
+
+	if action == tview.MouseLeftDown && win.desktop.mayMove(win) {
 		x, y, w, h := win.GetRect()
 		atX, atY := event.Position()
 		if win.border && atY >= y && atY < y+1 { // mouse in caption
@@ -137,32 +404,49 @@ func (wm *winMgr) DefaultMouseHandler(win *Window, action tview.MouseAction, eve
 				consumed = true
 			}
 		}
+		if consumed && win.desktop != nil && win.desktop.outlineDrag {
+			// Only draw an outline until the drag is done.
+			win.outlining = true
+			win.outlineX, win.outlineY, win.outlineW, win.outlineH = x, y, w, h
+		}
 	} else if action == tview.MouseLeftUp {
 		if win.moving || win.resizing != 0 {
 			win.moving = false
 			win.resizing = 0
+			if win.outlining {
+				win.outlining = false
+				win.SetRect(win.outlineX, win.outlineY, win.outlineW, win.outlineH)
+			}
 			// Move or resize is done, consume but don't capture mouse.
 			return true, nil
 		}
 	} else if action == tview.MouseMove {
 		x, y, w, h := win.GetRect()
+		if win.outlining {
+			x, y, w, h = win.outlineX, win.outlineY, win.outlineW, win.outlineH
+		}
 		atX, atY := event.Position()
 		if win.moving {
 			moveX, moveY := atX-x, atY-y
-			win.SetRect(x+(moveX-win.moveX), y+(moveY-win.moveY), w, h)
+			x, y = x+(moveX-win.moveX), y+(moveY-win.moveY)
 			consumed = true
 		} else if win.resizing != 0 {
-			neww := w
 			if win.resizing&1 != 0 {
-				neww = atX - x + 1
+				w = atX - x + 1
 			}
-			newh := h
 			if win.resizing&2 != 0 {
-				newh = atY - y + 1
+				h = atY - y + 1
 			}
-			win.SetRect(x, y, neww, newh)
 			consumed = true
 		}
+		if consumed {
+			if win.outlining {
+				w, h = win.boundSize(w, h)
+				win.outlineX, win.outlineY, win.outlineW, win.outlineH = x, y, w, h
+			} else {
+				win.SetRect(x, y, w, h)
+			}
+		}
 	}
 	if consumed {
 		capture = win
@@ -170,7 +454,7 @@ func (wm *winMgr) DefaultMouseHandler(win *Window, action tview.MouseAction, eve
 	}
 
 	if action == tview.MouseLeftDoubleClick {
-		if win.resizable && win.desktop != nil {
+		if win.resizable && win.desktop != nil && win.desktop.mayMove(win) {
 			_, y, _, _ := win.GetRect()
 			_, atY := event.Position()
 			if win.border && atY >= y && atY < y+1 { // mouse in caption
@@ -188,7 +472,13 @@ func (wm *winMgr) DefaultMouseHandler(win *Window, action tview.MouseAction, eve
 	return
 }
 
-var defWinMgr = &winMgr{theme: DefaultWindowTheme}
+var defWinMgr = &winMgr{theme: DefaultWindowTheme.ownFallbacks()}
+
+// NewWindowManager creates a window manager which works like DefaultWindowManager,
+// but with its own theme, so that desktops can have different themes.
+func NewWindowManager(theme WindowTheme) WindowManager {
+	return &winMgr{theme: theme.ownFallbacks()}
+}
 
 // DefaultWindowManager is the default window manager.
 // Most likely when making your own window manager, you'll want to embed this one.
@@ -202,21 +492,3 @@ const (
 	Minimized
 	Maximized
 )
-
-type WindowTheme struct {
-	TitleAlign               int
-	ActiveCaptionTextColor   tcell.Color
-	ActiveCaptionColor       tcell.Color
-	InactiveCaptionTextColor tcell.Color
-	InactiveCaptionColor     tcell.Color
-}
-
-// DefaultWindowTheme is the default desktop theme.
-// These colors were chosen to look decent and readable in most color counts.
-var DefaultWindowTheme = WindowTheme{
-	TitleAlign:               tview.AlignLeft,
-	ActiveCaptionTextColor:   tcell.ColorValid + 230,
-	ActiveCaptionColor:       tcell.ColorValid + 26,
-	InactiveCaptionTextColor: tcell.ColorValid + 15,
-	InactiveCaptionColor:     tcell.ColorValid + 239,
-}
@@ -0,0 +1,112 @@
+// Copyright (C) 2019 Christopher E. Miller
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tuix_test
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/millerlogic/tuix"
+	"github.com/millerlogic/tuix/tuixtest"
+)
+
+func TestWindowOwnColors(t *testing.T) {
+	h := tuixtest.New(t, 40, 12)
+	defer h.Close()
+	win := tuix.NewWindow()
+	win.SetTitle("A")
+	win.SetBorder(true).SetRect(2, 2, 20, 8)
+	win.SetBackgroundColor(tcell.ColorRed)
+	win.SetBorderColor(tcell.ColorLime)
+	h.Desktop.AddWindow(win)
+	h.Draw()
+	h.Draw()
+	if color := win.GetBackgroundColor(); color != tcell.ColorRed {
+		t.Errorf("background color is %v after drawing", color)
+	}
+	if _, style := h.Cell(5, 5); style != tcell.StyleDefault.Background(tcell.ColorRed) {
+		t.Errorf("window background style is %v", style)
+	}
+	if _, style := h.Cell(2, 5); style != tcell.StyleDefault.Foreground(tcell.ColorLime).Background(tcell.ColorRed) {
+		t.Errorf("window border style is %v", style)
+	}
+
+	h.Desktop.SetWindowManager(tuix.NewWindowManager(tuix.ClassicWindowTheme))
+	h.Desktop.SetTheme(tuix.DarkWindowTheme)
+	h.Draw()
+	if color := win.GetBackgroundColor(); color != tcell.ColorRed {
+		t.Errorf("background color is %v after changing the theme", color)
+	}
+}
+
+func TestWindowThemeColors(t *testing.T) {
+	h := tuixtest.New(t, 40, 12)
+	defer h.Close()
+	win := tuix.NewWindow()
+	win.SetBorder(true).SetRect(2, 2, 20, 8)
+	h.Desktop.AddWindow(win)
+	h.Draw()
+	theme := h.Desktop.GetTheme()
+	if _, style := h.Cell(5, 5); style != tcell.StyleDefault.Background(theme.ClientBackgroundColor) {
+		t.Errorf("window background style is %v", style)
+	}
+	if color := win.GetBackgroundColor(); color != tcell.ColorDefault {
+		t.Errorf("theme changed the window's background color to %v", color)
+	}
+}
+
+func TestDesktopBackgroundColor(t *testing.T) {
+	h := tuixtest.New(t, 20, 6)
+	defer h.Close()
+	theme := h.Desktop.GetTheme()
+	if _, style := h.Cell(0, 0); style != tcell.StyleDefault.Background(theme.DesktopColor) {
+		t.Errorf("desktop style is %v, expected the theme's", style)
+	}
+	h.Desktop.SetBackgroundColor(tcell.ColorNavy)
+	h.Draw()
+	if _, style := h.Cell(0, 0); style != tcell.StyleDefault.Background(tcell.ColorNavy) {
+		t.Errorf("desktop style is %v, expected its own", style)
+	}
+}
+
+func TestShadowOfCoveredWindow(t *testing.T) {
+	h := tuixtest.New(t, 40, 12)
+	defer h.Close()
+	h.Desktop.SetWindowManager(tuix.NewWindowManager(tuix.DarkWindowTheme))
+	textWindow(h, "A", "", 4, 2, 14, 5)
+	textWindow(h, "B", "", 2, 2, 16, 7)
+	// B covers A, but A's shadow darkens B's shadow more.
+	_, once := h.Cell(18, 8)
+	if _, twice := h.Cell(18, 4); twice == once {
+		t.Error("shadow of the covered window is not drawn")
+	}
+}
+
+func TestCaptionButtonsNarrow(t *testing.T) {
+	h := tuixtest.New(t, 40, 12)
+	defer h.Close()
+	theme := tuix.ClassicWindowTheme
+	theme.MinimizeButton, theme.MaximizeButton, theme.CloseButton = "[_]", "[^]", "[x]"
+	h.Desktop.SetWindowManager(tuix.NewWindowManager(theme))
+	win := tuix.NewWindow().SetResizable(true).SetClosable(true)
+	win.SetTitle("Narrow")
+	win.SetBorder(true).SetRect(2, 2, 12, 5) // The narrowest with a border.
+	h.Desktop.AddWindow(win)
+	h.Draw()
+	// Close and maximize fit, minimize would cover the left corner.
+	h.AssertText(6, 2, "[^]")
+	h.AssertText(10, 2, "[x]")
+	if c, _ := h.Cell(2, 2); c == '[' || c == '_' {
+		t.Errorf("left corner is %q, covered by a button", c)
+	}
+	h.Click(3, 2)
+	h.AssertState(win, tuix.Restored)
+	h.Drag(3, 2, 5, 4) // Moves from the title, which has no button.
+	h.AssertRect(win, 4, 4, 12, 5)
+	h.Click(9, 4)
+	h.AssertState(win, tuix.Maximized)
+}